@@ -1,21 +1,24 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"dirsearch-go/pkg/config"
+	"dirsearch-go/pkg/cookies"
 	"dirsearch-go/pkg/logger"
 	"dirsearch-go/pkg/logo"
 	"dirsearch-go/pkg/output"
 	"dirsearch-go/pkg/scanner"
-	"strings"
+	"dirsearch-go/pkg/scheduler"
+	"dirsearch-go/pkg/wordlist"
 
 	"github.com/schollz/progressbar/v3"
 )
@@ -23,6 +26,12 @@ import (
 // 版本信息，会在构建时通过 -ldflags 注入
 var version = "v0.01"
 
+// 模板任务分批提交，排队任务超过该数量时等待工作线程消费
+const (
+	comboBatchSize  = 1000
+	comboQueueLimit = 10000
+)
+
 // 为输出通道定义消息类型
 type progressIncrement int
 type progressMaxChange int
@@ -35,12 +44,20 @@ type statusMessage struct {
 type App struct {
 	config     *config.Config
 	logger     *logger.Logger
-	scanner    *scanner.Scanner
 	writer     output.Writer
 	progress   *progressbar.ProgressBar
 	ctx        context.Context
 	cancel     context.CancelFunc
-	outputChan chan interface{} // 用于结果和进度更新的统一通道
+	outputChan chan interface{}   // 用于结果和进度更新的统一通道
+	targets    []*targetScan      // 所有目标，按顺序扫描
+	wordlists  []wordlist.List    // 所有词典及其关键字，所有目标共用
+	words      []string           // 展开后的第一个词典，所有目录共用
+	jar        *cookies.Jar       // 所有目标共用的 Cookie 罐，未启用时为 nil
+	limiter    *scheduler.Limiter // 所有目标共用的请求并发和速率限制
+
+	// 以下字段只在 outputManager 中访问
+	targetWriters map[string]output.Writer  // 按目标单独输出时，各目标的文件输出器
+	summaries     map[string]*targetSummary // 各目标的扫描摘要
 }
 
 // NewApp 创建新的应用程序实例
@@ -55,22 +72,6 @@ func NewApp() (*App, error) {
 		return nil, fmt.Errorf("解析命令行参数失败: %w", err)
 	}
 
-	// 如果指定了配置文件，加载配置
-	if configFile != "" {
-		fileCfg, err := config.LoadFromFile(configFile)
-		if err != nil {
-			return nil, fmt.Errorf("加载配置文件失败: %w", err)
-		}
-		// 合并配置：命令行 > 配置文件 > 默认值
-		if cfg.Target == "" {
-			cfg.Target = fileCfg.Target
-		}
-		if cfg.Wordlist == "" {
-			cfg.Wordlist = fileCfg.Wordlist
-		}
-		// ... 其他配置项的合并
-	}
-
 	// 验证配置
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("配置验证失败: %w", err)
@@ -93,11 +94,8 @@ func NewApp() (*App, error) {
 	if err != nil {
 		return nil, fmt.Errorf("创建日志记录器失败: %w", err)
 	}
-
-	// 创建扫描器
-	scan, err := scanner.New(cfg, log)
-	if err != nil {
-		return nil, fmt.Errorf("创建扫描器失败: %w", err)
+	if configFile != "" {
+		log.Debug("已加载配置文件", "file", configFile)
 	}
 
 	// 创建输出器
@@ -107,8 +105,8 @@ func NewApp() (*App, error) {
 	consoleWriter := output.NewConsoleWriter(cfg.Output.Verbose)
 	writers = append(writers, consoleWriter)
 
-	// 如果指定了文件输出，添加缓冲文件写入器
-	if cfg.Output.File != "" {
+	// 如果指定了文件输出，添加缓冲文件写入器；文件名包含目标占位符时在每个目标开始时单独创建
+	if cfg.Output.File != "" && !output.IsFilenameTemplate(cfg.Output.File) {
 		fileWriter, err := output.CreateBufferedWriter(cfg.Output.Format, cfg.Output.File, cfg.Output.Verbose)
 		if err != nil {
 			return nil, fmt.Errorf("创建文件输出器失败: %w", err)
@@ -127,13 +125,42 @@ func NewApp() (*App, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	app := &App{
-		config:     cfg,
-		logger:     log,
-		scanner:    scan,
-		writer:     writer,
-		ctx:        ctx,
-		cancel:     cancel,
-		outputChan: make(chan interface{}, cfg.Threads*2), // 带缓冲的通道
+		config:        cfg,
+		logger:        log,
+		writer:        writer,
+		ctx:           ctx,
+		cancel:        cancel,
+		outputChan:    make(chan interface{}, cfg.Threads*2), // 带缓冲的通道
+		targetWriters: make(map[string]output.Writer),
+		summaries:     make(map[string]*targetSummary),
+	}
+	app.limiter = scheduler.NewLimiter(app.limits())
+
+	// Cookie 罐在所有目标之间共用，Cookie 按域名匹配，不会发送给其他主机；登录建立的会话同样保存在罐中
+	if cfg.Cookies.JarEnabled() || cfg.Login.Enabled() {
+		app.jar = cookies.New()
+		if cfg.Cookies.File != "" {
+			count, err := app.jar.Load(cfg.Cookies.File)
+			if err != nil {
+				return nil, fmt.Errorf("导入Cookie文件失败: %w", err)
+			}
+			log.Debug("已导入Cookie文件", "file", cfg.Cookies.File, "cookies", count)
+		}
+	}
+
+	// 为每个目标创建扫描器，目标之间共用词典和 Cookie 罐
+	for _, name := range cfg.TargetList() {
+		if err := cfg.ForTarget(name).Validate(); err != nil {
+			return nil, fmt.Errorf("配置验证失败 (%s): %w", name, err)
+		}
+		t, err := newTargetScan(app, name)
+		if err != nil {
+			return nil, err
+		}
+		app.targets = append(app.targets, t)
+	}
+	if len(app.targets) > 1 {
+		log.Info("已加载目标列表", "targets", len(app.targets))
 	}
 
 	return app, nil
@@ -143,9 +170,19 @@ func NewApp() (*App, error) {
 func (a *App) Run() error {
 	a.setupSignalHandling()
 
-	totalJobs, err := a.calculateTotalJobs(a.config.Wordlist)
-	if err != nil {
-		return fmt.Errorf("计算总任务数失败: %w", err)
+	for _, spec := range a.config.WordlistSpecs() {
+		words, err := wordlist.Load(spec.Path, a.wordlistOptions())
+		if err != nil {
+			return fmt.Errorf("加载词典失败: %w", err)
+		}
+		a.wordlists = append(a.wordlists, wordlist.List{Keyword: spec.Keyword, Words: words})
+	}
+	a.words = a.wordlists[0].Words
+
+	// 进度条覆盖所有目标的初始任务
+	totalJobs := 0
+	for _, t := range a.targets {
+		totalJobs += t.calculateTotalJobs()
 	}
 
 	a.progress = progressbar.NewOptions(totalJobs,
@@ -182,6 +219,15 @@ func (a *App) Run() error {
 		}
 	}
 
+	if a.jar != nil && a.config.Cookies.Export != "" {
+		if err := a.jar.Save(a.config.Cookies.Export); err != nil {
+			a.logger.Error("导出Cookie失败", "error", err)
+		} else {
+			a.logger.Info("已导出Cookie", "file", a.config.Cookies.Export)
+		}
+	}
+
+	a.printSummaries()
 	a.logger.Info("扫描完成")
 	return nil
 }
@@ -223,6 +269,12 @@ func (a *App) outputManager(wg *sync.WaitGroup) {
 				if err := a.writer.Write(v); err != nil {
 					a.logger.Error("写入结果失败", "error", err)
 				}
+				if w, ok := a.targetWriters[v.Target]; ok {
+					if err := w.Write(v); err != nil {
+						a.logger.Error("写入结果失败", "target", v.Target, "error", err)
+					}
+				}
+				a.record(v)
 
 				// 输出结果后重新显示进度条
 				a.restoreProgressBar()
@@ -243,21 +295,18 @@ func (a *App) outputManager(wg *sync.WaitGroup) {
 
 				// 恢复进度条
 				a.restoreProgressBar()
+			case targetStarted:
+				a.startTarget(v.target)
+			case targetFinished:
+				a.finishTarget(v)
 			}
 		}
 	}
 }
 
-// scan 执行扫描
+// scan 扫描所有目标
+// 目标依次完成校准和预扫描后加入线程池，所有目标的任务交替执行，共用全局的线程数和速率限制
 func (a *App) scan() error {
-	file, err := os.Open(a.config.Wordlist)
-	if err != nil {
-		return fmt.Errorf("打开词典文件失败: %w", err)
-	}
-	defer file.Close()
-
-	jobs := make(chan string, a.config.Threads*2)
-	var workerWg sync.WaitGroup
 	var outputWg sync.WaitGroup
 
 	// 启动 outputManager
@@ -265,109 +314,170 @@ func (a *App) scan() error {
 	go a.outputManager(&outputWg)
 
 	// 启动工作线程
-	for i := 0; i < a.config.Threads; i++ {
-		workerWg.Add(1)
-		go a.worker(jobs, &workerWg)
-	}
+	pool := scheduler.NewPool(a.limiter)
+	var poolWg sync.WaitGroup
+	poolWg.Add(1)
+	go func() {
+		defer poolWg.Done()
+		pool.Run(a.ctx, a.config.Threads)
+	}()
 
-	// 读取词典并发送任务
-	fileScanner := bufio.NewScanner(file)
-	for fileScanner.Scan() {
-		select {
-		case <-a.ctx.Done():
-			goto cleanup
-		default:
-			word := fileScanner.Text()
-			if strings.Contains(word, "%EXT%") {
-				for _, ext := range a.config.Scanner.Extensions {
-					// 智能处理扩展名替换，避免双点号
-					var extToUse string
-					if strings.Contains(word, ".%EXT%") {
-						// 如果占位符前已经有点号，直接使用扩展名（不加点号）
-						extToUse = ext
-					} else {
-						// 如果占位符前没有点号，添加点号
-						if !strings.HasPrefix(ext, ".") {
-							extToUse = "." + ext
-						} else {
-							extToUse = ext
-						}
-					}
-					newWord := strings.ReplaceAll(word, "%EXT%", extToUse)
-					jobs <- newWord
-				}
-			} else {
-				jobs <- word
-			}
+	var targetWg sync.WaitGroup
+	for _, t := range a.targets {
+		if a.ctx.Err() != nil {
+			break
 		}
-	}
+		start := time.Now()
+		t.prepare()
 
-cleanup:
-	if err := fileScanner.Err(); err != nil {
-		a.logger.Error("读取词典文件失败", "error", err)
+		targetWg.Add(1)
+		go func() {
+			defer targetWg.Done()
+			t.run(pool, start)
+		}()
 	}
 
-	close(jobs)
-	workerWg.Wait()
+	// 所有目标完成后关闭线程池
+	targetWg.Wait()
+	pool.Close()
+	poolWg.Wait()
+
 	close(a.outputChan)
 	outputWg.Wait()
 
+	// 扫描被中断时，未结束的目标输出文件在这里关闭
+	for name, w := range a.targetWriters {
+		if err := w.Close(); err != nil {
+			a.logger.Error("关闭输出器失败", "target", name, "error", err)
+		}
+		delete(a.targetWriters, name)
+	}
+
 	return a.ctx.Err()
 }
 
-// worker 工作线程
-func (a *App) worker(jobs <-chan string, wg *sync.WaitGroup) {
-	defer wg.Done()
-	for word := range jobs {
-		select {
-		case <-a.ctx.Done():
+// limits 根据配置生成请求的并发和速率限制
+func (a *App) limits() scheduler.Limits {
+	limits := scheduler.Limits{
+		HostConcurrency: a.config.HostThreads,
+		HostRate:        a.config.RateLimit.HostRequestsPerSecond,
+	}
+	if a.config.RateLimit.Enabled {
+		limits.Rate = a.config.RateLimit.RequestsPerSecond
+	}
+	return limits
+}
+
+// startTarget 记录目标开始扫描：多目标时输出提示，按目标输出时创建该目标的输出文件
+func (a *App) startTarget(t *targetScan) {
+	a.summaries[t.name] = &targetSummary{target: t.name, byStatus: make(map[int]int)}
+
+	if len(a.targets) > 1 {
+		a.clearProgressBar()
+		fmt.Fprintf(os.Stderr, "[*] 开始扫描目标: %s\n", t.name)
+		a.restoreProgressBar()
+	}
+
+	if output.IsFilenameTemplate(a.config.Output.File) {
+		filename := output.TargetFilename(a.config.Output.File, t.name)
+		w, err := output.CreateBufferedWriter(a.config.Output.Format, filename, a.config.Output.Verbose)
+		if err != nil {
+			a.logger.Error("创建文件输出器失败", "target", t.name, "file", filename, "error", err)
 			return
-		default:
-			a.outputChan <- progressIncrement(1)
-			result, err := a.scanner.ScanURL(a.ctx, a.config.Target, word, 0)
-			if err != nil {
-				a.logger.Error("扫描URL失败", "word", word, "error", err)
-				continue
-			}
-			if result != nil {
-				a.outputChan <- result
-				if a.config.Recursive && result.StatusCode >= 200 && result.StatusCode < 400 {
-					a.recursiveScan(result, 1)
-				}
-			}
 		}
+		a.targetWriters[t.name] = w
 	}
 }
 
-// recursiveScan 递归扫描
-func (a *App) recursiveScan(parentResult *scanner.Result, depth int) {
-	if depth > a.config.MaxDepth {
+// finishTarget 记录目标扫描结束，并关闭该目标的输出文件
+func (a *App) finishTarget(v targetFinished) {
+	if summary, ok := a.summaries[v.target.name]; ok {
+		summary.requests = v.target.requests.Load()
+		summary.duration = v.duration
+	}
+
+	if len(a.targets) > 1 {
+		a.clearProgressBar()
+		fmt.Fprintf(os.Stderr, "[*] 目标扫描完成: %s (%s)\n", v.target.name, v.duration.Round(time.Millisecond))
+		a.restoreProgressBar()
+	}
+
+	if w, ok := a.targetWriters[v.target.name]; ok {
+		if err := w.Close(); err != nil {
+			a.logger.Error("关闭输出器失败", "target", v.target.name, "error", err)
+		}
+		delete(a.targetWriters, v.target.name)
+	}
+}
+
+// record 将结果计入所属目标的摘要
+func (a *App) record(result *scanner.Result) {
+	summary, ok := a.summaries[result.Target]
+	if !ok || result.Calibration {
 		return
 	}
+	if result.Error != "" {
+		summary.errors++
+		return
+	}
+	summary.found++
+	summary.byStatus[result.StatusCode/100]++
+}
 
-	paths := a.scanner.ExtractPaths(parentResult)
-	if len(paths) > 0 {
-		a.outputChan <- progressMaxChange(len(paths))
+// printSummaries 多目标扫描结束后输出每个目标的摘要
+func (a *App) printSummaries() {
+	if len(a.targets) <= 1 {
+		return
 	}
 
-	for _, path := range paths {
-		select {
-		case <-a.ctx.Done():
-			return
-		default:
-			a.outputChan <- progressIncrement(1)
-			result, err := a.scanner.ScanURL(a.ctx, a.config.Target, path, depth)
-			if err != nil {
-				a.logger.Error("递归扫描失败", "path", path, "depth", depth, "error", err)
-				continue
-			}
-			if result != nil {
-				a.outputChan <- result
-				if result.StatusCode >= 200 && result.StatusCode < 400 {
-					a.recursiveScan(result, depth+1)
-				}
+	fmt.Fprintln(os.Stdout, "\n===== 扫描摘要 =====")
+	for _, t := range a.targets {
+		summary, ok := a.summaries[t.name]
+		if !ok {
+			fmt.Fprintf(os.Stdout, "%s  未扫描\n", t.name)
+			continue
+		}
+
+		var classes []string
+		for class := 2; class <= 5; class++ {
+			if n := summary.byStatus[class]; n > 0 {
+				classes = append(classes, fmt.Sprintf("%dxx: %d", class, n))
 			}
 		}
+		found := strconv.Itoa(summary.found)
+		if len(classes) > 0 {
+			found += " (" + strings.Join(classes, ", ") + ")"
+		}
+		fmt.Fprintf(os.Stdout, "%s  请求: %d  发现: %s  错误: %d  耗时: %s\n",
+			summary.target, summary.requests, found, summary.errors, summary.duration.Round(time.Millisecond))
+	}
+}
+
+// wordlistOptions 根据配置生成词典变换选项
+// 虚拟主机扫描的词条是主机名，不展开扩展名，包含 %EXT% 的词条会被丢弃
+func (a *App) wordlistOptions() wordlist.Options {
+	m := a.config.Mutations
+	if a.config.VhostMode() {
+		return wordlist.Options{
+			ExcludeExtensions: m.ExcludeExtensions,
+			RemoveExtensions:  m.RemoveExtensions,
+			Prefixes:          m.Prefixes,
+			Suffixes:          m.Suffixes,
+			Uppercase:         m.Uppercase,
+			Lowercase:         m.Lowercase,
+			Capitalize:        m.Capitalize,
+		}
+	}
+	return wordlist.Options{
+		Extensions:        a.config.Scanner.Extensions,
+		ForceExtensions:   m.ForceExtensions,
+		ExcludeExtensions: m.ExcludeExtensions,
+		RemoveExtensions:  m.RemoveExtensions,
+		Prefixes:          m.Prefixes,
+		Suffixes:          m.Suffixes,
+		Uppercase:         m.Uppercase,
+		Lowercase:         m.Lowercase,
+		Capitalize:        m.Capitalize,
 	}
 }
 
@@ -415,33 +525,11 @@ func (a *App) flushMultiWriter(multiWriter *output.MultiWriter) error {
 	return multiWriter.Flush()
 }
 
-// calculateTotalJobs 计算总任务数
-func (a *App) calculateTotalJobs(path string) (int, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return 0, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	count := 0
-	numExtensions := len(a.config.Scanner.Extensions)
-
-	for scanner.Scan() {
-		if strings.Contains(scanner.Text(), "%EXT%") {
-			count += numExtensions
-		} else {
-			count++
-		}
-	}
-	return count, scanner.Err()
-}
-
 // Close 关闭应用程序
 func (a *App) Close() {
 	a.cancel()
-	if a.scanner != nil {
-		a.scanner.Close()
+	for _, t := range a.targets {
+		t.scanner.Close()
 	}
 	if a.logger != nil {
 		a.logger.Close()
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"dirsearch-go/pkg/config"
+	"dirsearch-go/pkg/scanner"
+	"dirsearch-go/pkg/scheduler"
+	"dirsearch-go/pkg/wordlist"
+)
+
+// targetScan 单个目标的扫描状态
+// 每个目标有独立的配置副本、扫描器（软404基线）和任务调度器，词典和工作线程由所有目标共用
+type targetScan struct {
+	app       *App
+	name      string // 目标在列表中的原始写法，用于输出和摘要
+	host      string // 目标主机，同一主机的目标共用并发和速率限制
+	config    *config.Config
+	scanner   *scanner.Scanner
+	scheduler *scheduler.Scheduler
+
+	dirsMu     sync.Mutex
+	queuedDirs map[string]bool // 已加入递归的目录
+	linkDirs   map[string]bool // 为发现的链接校准过的目录，数量不超过 maxLinkCalibrations
+
+	requests atomic.Int64 // 已执行的任务数
+	skipped  bool         // 登录失败，不扫描该目标
+}
+
+// targetStarted 目标开始扫描，outputManager 据此输出提示并创建单独的输出文件
+type targetStarted struct {
+	target *targetScan
+}
+
+// targetFinished 目标扫描结束，outputManager 据此关闭该目标的输出文件并记录摘要
+type targetFinished struct {
+	target   *targetScan
+	duration time.Duration
+}
+
+// targetSummary 单个目标的扫描摘要
+type targetSummary struct {
+	target   string
+	requests int64
+	found    int
+	errors   int
+	byStatus map[int]int // 按状态码类别 (2, 3, 4, 5) 统计的发现数
+	duration time.Duration
+}
+
+// newTargetScan 为目标创建扫描器和调度器
+func newTargetScan(a *App, name string) (*targetScan, error) {
+	cfg := a.config.ForTarget(name)
+	u, err := url.Parse(cfg.Target)
+	if err != nil {
+		return nil, fmt.Errorf("解析目标URL失败 (%s): %w", name, err)
+	}
+	scan, err := scanner.New(cfg, a.logger, a.jar, a.limiter)
+	if err != nil {
+		return nil, fmt.Errorf("创建扫描器失败 (%s): %w", name, err)
+	}
+
+	return &targetScan{
+		app:        a,
+		name:       name,
+		host:       strings.ToLower(u.Host),
+		config:     cfg,
+		scanner:    scan,
+		scheduler:  scheduler.New(scheduler.Order(cfg.Recursion.Order)),
+		queuedDirs: make(map[string]bool),
+		linkDirs:   make(map[string]bool),
+	}, nil
+}
+
+// prepare 提交目标根目录的任务，并在加入线程池之前完成登录、软404校准和预扫描
+func (t *targetScan) prepare() {
+	t.app.outputChan <- targetStarted{target: t}
+
+	// 登录失败时扫描结果没有意义，跳过该目标并从进度条中扣除它的任务
+	if err := t.scanner.Login(t.app.ctx); err != nil {
+		t.app.logger.Error("登录失败，跳过目标", "target", t.name, "error", err)
+		t.skipped = true
+		t.app.outputChan <- progressMaxChange(-t.calculateTotalJobs())
+		return
+	}
+
+	// 目标根目录的词典任务，模板模式的词条组合数量可能很大，在加入线程池后分批提交
+	t.queuedDirs[strings.TrimRight(t.config.Target, "/")+"/"] = true
+	if !t.config.FuzzMode() {
+		t.scheduler.Push(t.wordlistJobs(t.config.Target, 0)...)
+	}
+
+	// 建立软404基线，虚拟主机扫描以随机的不存在虚拟主机作为基线
+	if t.config.Calibration.Enabled {
+		switch {
+		case t.config.VhostMode():
+			t.calibrateVhost()
+		case t.config.FuzzMode():
+			t.calibrateFuzz()
+		default:
+			t.calibrate(t.config.Target)
+		}
+	}
+
+	// 从 robots.txt、sitemap.xml 和 security.txt 收集种子路径
+	if t.config.Seeds {
+		t.queueSeeds()
+	}
+}
+
+// run 将目标加入线程池并等待所有任务完成
+func (t *targetScan) run(pool *scheduler.Pool, start time.Time) {
+	a := t.app
+	done := pool.Add(t.host, t.scheduler, t.process)
+
+	if t.config.FuzzMode() && !t.skipped {
+		t.queueCombinations()
+	}
+
+	// 初始任务已全部入队，之后只有递归会产生新任务
+	t.scheduler.Close()
+
+	select {
+	case <-done:
+		a.outputChan <- targetFinished{target: t, duration: time.Since(start)}
+	case <-a.ctx.Done():
+	}
+}
+
+// calculateTotalJobs 计算初始任务数：模板模式为词典的组合数，否则为目标根目录下去重后的URL数
+func (t *targetScan) calculateTotalJobs() int {
+	if t.config.FuzzMode() {
+		return wordlist.Count(wordlist.Mode(t.config.WordlistMode), t.app.wordlists)
+	}
+
+	urls := make(map[string]struct{}, len(t.app.words))
+	for _, job := range t.wordlistJobs(t.config.Target, 0) {
+		urls[job.URL()] = struct{}{}
+	}
+	return len(urls)
+}
+
+// queueCombinations 按组合方式生成模板任务并分批提交，排队的任务过多时等待工作线程消费
+func (t *targetScan) queueCombinations() {
+	batch := make([]scheduler.Job, 0, comboBatchSize)
+	flush := func() bool {
+		if !t.scheduler.Wait(t.app.ctx, comboQueueLimit) {
+			return false
+		}
+		t.scheduler.Push(batch...)
+		batch = batch[:0]
+		return true
+	}
+
+	for payload := range wordlist.Combinations(wordlist.Mode(t.config.WordlistMode), t.app.wordlists) {
+		batch = append(batch, scheduler.Job{Payload: payload, Source: scanner.SourceWordlist})
+		if len(batch) == comboBatchSize && !flush() {
+			return
+		}
+	}
+	if len(batch) > 0 {
+		flush()
+	}
+}
+
+// wordlistJobs 为 base 目录生成全部词典任务
+func (t *targetScan) wordlistJobs(base string, depth int) []scheduler.Job {
+	words := t.app.words
+	jobs := make([]scheduler.Job, 0, len(words))
+	for _, word := range words {
+		jobs = append(jobs, scheduler.Job{Base: base, Word: word, Depth: depth, Source: scanner.SourceWordlist})
+	}
+	return jobs
+}
+
+// enqueue 将递归任务交给调度器，并同步调整进度条
+func (t *targetScan) enqueue(jobs ...scheduler.Job) {
+	if added := t.scheduler.Push(jobs...); added > 0 {
+		t.app.outputChan <- progressMaxChange(added)
+	}
+}
+
+// calibrate 校准目录的软404基线，并将探测结果交给输出器记录
+// 同一目录只会校准一次，重复调用直接返回
+func (t *targetScan) calibrate(target string) {
+	probes, err := t.scanner.Calibrate(t.app.ctx, target)
+	if err != nil {
+		t.app.logger.Warn("校准失败", "target", target, "error", err)
+	}
+	t.output(probes...)
+}
+
+// calibrateFuzz 校准 FUZZ 请求模板的软404基线
+func (t *targetScan) calibrateFuzz() {
+	probes, err := t.scanner.CalibrateFuzz(t.app.ctx)
+	if err != nil {
+		t.app.logger.Warn("校准失败", "target", t.config.Target, "error", err)
+	}
+	t.output(probes...)
+}
+
+// calibrateVhost 校准虚拟主机扫描的基线
+func (t *targetScan) calibrateVhost() {
+	probes, err := t.scanner.CalibrateVhost(t.app.ctx)
+	if err != nil {
+		t.app.logger.Warn("校准失败", "target", t.config.Target, "error", err)
+	}
+	t.output(probes...)
+}
+
+// output 标记结果所属的目标并交给输出器
+func (t *targetScan) output(results ...*scanner.Result) {
+	for _, result := range results {
+		result.Target = t.name
+		t.app.outputChan <- result
+	}
+}
+
+// process 执行单个任务，并将递归产生的新任务入队
+func (t *targetScan) process(j scheduler.Job) {
+	a := t.app
+	a.outputChan <- progressIncrement(1)
+	t.requests.Add(1)
+
+	if j.Source != scanner.SourceWordlist && !t.config.VhostMode() {
+		t.calibrateLink(j.URL())
+	}
+
+	var result *scanner.Result
+	var err error
+	switch {
+	case j.Payload != nil:
+		result, err = t.scanner.ScanFuzz(a.ctx, j.Payload, j.Depth)
+	case t.config.VhostMode():
+		result, err = t.scanner.ScanVhost(a.ctx, j.Word)
+	default:
+		result, err = t.scanner.ScanURL(a.ctx, j.Base, j.Word, j.Depth)
+	}
+	if err != nil {
+		a.logger.Error("扫描URL失败", "word", j.Word, "error", err)
+		return
+	}
+	if result == nil {
+		return
+	}
+
+	// 被状态码过滤的目录只用于递归，不输出
+	if result.Hidden {
+		result.DiscardBody()
+		t.queueDirectory(result, j.Depth+1)
+		return
+	}
+
+	result.Source = j.Source
+	result.DerivedFrom = j.DerivedFrom
+	t.output(result)
+
+	if t.config.Backups.Enabled {
+		t.queueDiscovered(t.scanner.BackupLinks(result), j.Depth)
+	}
+
+	followLinks := t.config.Crawl || (t.config.Recursive && t.config.Recursion.Mode != config.RecursionDirs)
+	if followLinks && result.StatusCode >= 200 && result.StatusCode < 400 {
+		t.queueLinks(result, j.Depth+1)
+	}
+	if t.config.Recursive && t.config.Recursion.Mode != config.RecursionLinks && t.scanner.IsDirectory(result) {
+		t.queueDirectory(result, j.Depth+1)
+	}
+
+	// 链接和备份文件已提取完毕，结果之后只用于输出
+	result.DiscardBody()
+}
+
+// queueDirectory 在发现的目录下重新爆破整个词典
+func (t *targetScan) queueDirectory(result *scanner.Result, depth int) {
+	if depth > t.config.MaxDepth {
+		return
+	}
+
+	dir := scanner.DirectoryURL(result)
+
+	t.dirsMu.Lock()
+	if t.queuedDirs[dir] {
+		t.dirsMu.Unlock()
+		return
+	}
+	t.queuedDirs[dir] = true
+	t.dirsMu.Unlock()
+
+	// 进入新目录前先为其建立软404基线
+	if t.config.Calibration.Enabled {
+		t.calibrate(dir)
+	}
+
+	t.app.outputChan <- statusMessage{message: fmt.Sprintf("[*] 加入递归目录: %s", dir), toStderr: true}
+	t.enqueue(t.wordlistJobs(dir, depth)...)
+}
+
+// queueSeeds 执行预扫描，将发现的路径作为初始任务入队
+func (t *targetScan) queueSeeds() {
+	seeds := t.scanner.DiscoverSeeds(t.app.ctx)
+	t.queueDiscovered(seeds, 0)
+	t.app.logger.Info("预扫描完成", "target", t.name, "paths", len(seeds))
+}
+
+// queueLinks 爬取页面中的链接和接口，作为新任务加入调度器
+func (t *targetScan) queueLinks(parentResult *scanner.Result, depth int) {
+	if depth > t.config.MaxDepth {
+		return
+	}
+
+	t.queueDiscovered(t.scanner.ExtractLinks(parentResult), depth)
+}
+
+// maxLinkCalibrations 每个目标最多为发现的链接校准的目录数
+const maxLinkCalibrations = 32
+
+// calibrateLink 在扫描爬取、预扫描或备份文件得到的链接前，为链接所在目录建立软404基线
+// 链接可能分散在大量目录中，超过 maxLinkCalibrations 个目录后不再校准，使用最近一级已校准目录的基线
+func (t *targetScan) calibrateLink(link string) {
+	if !t.config.Calibration.Enabled {
+		return
+	}
+	dir := scanner.ParentDirectory(link)
+	if dir == "" {
+		return
+	}
+
+	t.dirsMu.Lock()
+	// 递归目录在入队时已经校准
+	calibrate := !t.queuedDirs[dir] && (t.linkDirs[dir] || len(t.linkDirs) < maxLinkCalibrations)
+	if calibrate {
+		t.linkDirs[dir] = true
+	}
+	t.dirsMu.Unlock()
+
+	// 同一目录只会校准一次，其他任务等待校准完成
+	if calibrate {
+		t.calibrate(dir)
+	}
+}
+
+// queueDiscovered 将发现的链接转换为任务入队，所在目录的软404基线在执行任务时建立
+func (t *targetScan) queueDiscovered(links []scanner.Link, depth int) {
+	jobs := make([]scheduler.Job, 0, len(links))
+	for _, link := range links {
+		u, err := url.Parse(link.URL)
+		if err != nil {
+			continue
+		}
+
+		origin := u.Scheme + "://" + u.Host
+		jobs = append(jobs, scheduler.Job{
+			Base:        origin,
+			Word:        u.RequestURI(),
+			Depth:       depth,
+			Source:      link.Source,
+			DerivedFrom: link.DerivedFrom,
+		})
+	}
+	t.enqueue(jobs...)
+}
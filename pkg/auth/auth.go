@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// 认证方式
+const (
+	Basic  = "basic"
+	Digest = "digest"
+	Bearer = "bearer"
+	NTLM   = "ntlm"
+)
+
+// Credentials 认证信息
+type Credentials struct {
+	Type     string
+	Username string // NTLM 可写成 DOMAIN\user
+	Password string
+	Domain   string // NTLM 域，Username 中带有域时忽略
+	Token    string // Bearer 令牌
+	// Scope 允许发送认证信息的地址，只比较主机和端口，主机中的 * 匹配任意字符
+	// 其他主机（例如重定向到的外部站点）的请求不添加认证信息，也不应答质询。为空时不限制
+	Scope []string
+}
+
+// errBodyNotRewindable 请求体无法重新读取，不能在质询后重发
+var errBodyNotRewindable = errors.New("请求体无法重新发送")
+
+// New 根据认证方式包装 base，返回带认证的 RoundTripper
+// 请求中已经带有 Authorization 请求头时（例如通过 -H 指定）不做处理
+func New(creds Credentials, base *http.Transport) (http.RoundTripper, error) {
+	var transport http.RoundTripper
+	switch creds.Type {
+	case Basic:
+		token := base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+		transport = &headerTransport{base: base, value: "Basic " + token}
+	case Bearer:
+		transport = &headerTransport{base: base, value: "Bearer " + creds.Token}
+	case Digest:
+		transport = &digestTransport{base: base, creds: creds}
+	case NTLM:
+		transport = newNTLMTransport(base, creds)
+	default:
+		return nil, fmt.Errorf("不支持的认证方式: %s", creds.Type)
+	}
+
+	if len(creds.Scope) == 0 {
+		return transport, nil
+	}
+	scoped := &scopedTransport{auth: transport, base: base}
+	for _, raw := range creds.Scope {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("无效的认证范围 %s: %w", raw, err)
+		}
+		scoped.hosts = append(scoped.hosts, hostPort(u))
+	}
+	return scoped, nil
+}
+
+// scopedTransport 只对 Scope 中的主机使用带认证的 Transport，其他主机的请求直接通过 base 发送
+type scopedTransport struct {
+	auth  http.RoundTripper
+	base  *http.Transport
+	hosts []string // 允许的主机和端口，可以包含 *
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *scopedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := hostPort(req.URL)
+	for _, pattern := range t.hosts {
+		if matchHost(pattern, host) {
+			return t.auth.RoundTrip(req)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// CloseIdleConnections 关闭空闲连接
+func (t *scopedTransport) CloseIdleConnections() {
+	if c, ok := t.auth.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+	t.base.CloseIdleConnections()
+}
+
+// hostPort 返回小写的主机名和端口，缺省端口按协议补全
+func hostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "https":
+			port = "443"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(strings.ToLower(u.Hostname()), port)
+}
+
+// matchHost 判断主机是否匹配模式，模式中的 * 匹配任意字符
+func matchHost(pattern, host string) bool {
+	prefix, rest, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return pattern == host
+	}
+	if !strings.HasPrefix(host, prefix) {
+		return false
+	}
+	host = host[len(prefix):]
+	for i := 0; i <= len(host); i++ {
+		if matchHost(rest, host[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerTransport 为每个请求添加固定的 Authorization 请求头 (Basic, Bearer)
+type headerTransport struct {
+	base  *http.Transport
+	value string
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", t.value)
+	return t.base.RoundTrip(r)
+}
+
+// CloseIdleConnections 关闭空闲连接
+func (t *headerTransport) CloseIdleConnections() {
+	t.base.CloseIdleConnections()
+}
+
+// rewind 复制请求并重新获取请求体，用于质询应答过程中多次发送同一请求
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return r, nil
+	}
+	if req.GetBody == nil {
+		return nil, errBodyNotRewindable
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r.Body = body
+	return r, nil
+}
+
+// challenge 从 WWW-Authenticate 响应头中找到指定方式的质询，返回方式名之后的内容
+func challenge(resp *http.Response, scheme string) (string, bool) {
+	for _, value := range resp.Header.Values("WWW-Authenticate") {
+		name, rest, _ := strings.Cut(strings.TrimSpace(value), " ")
+		if strings.EqualFold(name, scheme) {
+			return strings.TrimSpace(rest), true
+		}
+	}
+	return "", false
+}
+
+// discard 读完并关闭响应体，使连接可以复用
+func discard(resp *http.Response) {
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestScopeRedirect 重定向到其他主机时不发送认证信息，也不应答其质询
+func TestScopeRedirect(t *testing.T) {
+	tests := []Credentials{
+		{Type: Basic, Username: "user", Password: "pass"},
+		{Type: Bearer, Token: "token"},
+		{Type: Digest, Username: "user", Password: "pass"},
+		{Type: NTLM, Username: "user", Password: "pass"},
+	}
+
+	for _, creds := range tests {
+		t.Run(creds.Type, func(t *testing.T) {
+			var mu sync.Mutex
+			var leaked []string
+			other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if value := r.Header.Get("Authorization"); value != "" {
+					mu.Lock()
+					leaked = append(leaked, value)
+					mu.Unlock()
+				}
+				w.Header().Add("WWW-Authenticate", `Digest realm="other", nonce="abc", qop="auth"`)
+				w.Header().Add("WWW-Authenticate", "NTLM")
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			defer other.Close()
+
+			var targetAuth string
+			target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				targetAuth = r.Header.Get("Authorization")
+				http.Redirect(w, r, other.URL+"/login", http.StatusFound)
+			}))
+			defer target.Close()
+
+			creds.Scope = []string{target.URL + "/app/"}
+			transport, err := New(creds, &http.Transport{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &http.Client{Transport: transport}
+
+			resp, err := client.Get(target.URL + "/admin")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Fatalf("状态码 = %d, want 重定向后的 401", resp.StatusCode)
+			}
+			if (creds.Type == Basic || creds.Type == Bearer) && targetAuth == "" {
+				t.Error("目标主机没有收到认证信息")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(leaked) > 0 {
+				t.Errorf("重定向到的主机收到了认证信息: %q", leaked)
+			}
+		})
+	}
+}
+
+func TestNewInvalidScope(t *testing.T) {
+	if _, err := New(Credentials{Type: Basic, Scope: []string{"http://example.com:port/"}}, &http.Transport{}); err == nil {
+		t.Error("New() 没有返回错误")
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		scope string
+		url   string
+		want  bool
+	}{
+		{"https://example.com/", "https://example.com/admin", true},
+		{"https://example.com/", "https://EXAMPLE.com:443/", true},
+		{"http://example.com/", "http://example.com:80/", true},
+		{"http://example.com:8080/", "http://example.com:8080/", true},
+		{"http://example.com/", "http://example.com:8080/", false},
+		{"http://example.com/", "https://example.com/", false},
+		{"https://example.com/", "https://www.example.com/", false},
+		{"https://example.com/", "https://example.com.evil.net/", false},
+		{"https://*.example.com/", "https://dev.example.com/", true},
+		{"https://*.example.com/", "https://a.b.example.com/", true},
+		{"https://*.example.com/", "https://example.com/", false},
+		{"https://*.example.com/", "https://dev.example.com.evil.net/", false},
+		{"http://[::1]/", "http://[::1]:80/", true},
+	}
+
+	for _, tt := range tests {
+		scope, err := http.NewRequest("GET", tt.scope, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest("GET", tt.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := matchHost(hostPort(scope.URL), hostPort(req.URL)); got != tt.want {
+			t.Errorf("matchHost(%s, %s) = %v, want %v", tt.scope, tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// digestTransport Digest 认证 (RFC 7616)
+// 收到质询后缓存 nonce，之后的请求直接携带应答并递增 nc；nonce 过期时服务器再次质询，更新后重发一次
+type digestTransport struct {
+	base  *http.Transport
+	creds Credentials
+
+	mu        sync.Mutex
+	challenge map[string]string // 最近一次质询的参数，为 nil 表示尚未收到质询
+	nc        int               // 当前 nonce 已使用的次数
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	r, err := rewind(req)
+	if err != nil {
+		return nil, err
+	}
+	auth, ok, err := t.authorize(r)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		r.Header.Set("Authorization", auth)
+	}
+	resp, err := t.base.RoundTrip(r)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	value, ok := challenge(resp, "Digest")
+	if !ok {
+		return resp, nil
+	}
+	params := parseParams(value)
+	if _, ok := digestHash(params["algorithm"]); !ok || params["nonce"] == "" {
+		return resp, nil
+	}
+
+	// 无法重发请求体时保留原始的 401 响应
+	retry, err := rewind(req)
+	if err != nil {
+		return resp, nil
+	}
+	discard(resp)
+
+	t.mu.Lock()
+	t.challenge = params
+	t.nc = 0
+	t.mu.Unlock()
+
+	auth, _, err = t.authorize(retry)
+	if err != nil {
+		return nil, err
+	}
+	retry.Header.Set("Authorization", auth)
+	return t.base.RoundTrip(retry)
+}
+
+// authorize 根据缓存的质询计算请求的 Authorization 请求头，尚未收到质询时返回 false
+func (t *digestTransport) authorize(req *http.Request) (string, bool, error) {
+	t.mu.Lock()
+	params := t.challenge
+	if params == nil {
+		t.mu.Unlock()
+		return "", false, nil
+	}
+	t.nc++
+	nc := fmt.Sprintf("%08x", t.nc)
+	t.mu.Unlock()
+
+	newHash, _ := digestHash(params["algorithm"])
+	h := func(s string) string {
+		hasher := newHash()
+		hasher.Write([]byte(s))
+		return hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	cnonce := make([]byte, 8)
+	if _, err := rand.Read(cnonce); err != nil {
+		return "", false, fmt.Errorf("生成 cnonce 失败: %w", err)
+	}
+	cnonceHex := hex.EncodeToString(cnonce)
+
+	realm, nonce := params["realm"], params["nonce"]
+	uri := req.URL.RequestURI()
+	ha1 := h(t.creds.Username + ":" + realm + ":" + t.creds.Password)
+	if strings.HasSuffix(strings.ToLower(params["algorithm"]), "-sess") {
+		ha1 = h(ha1 + ":" + nonce + ":" + cnonceHex)
+	}
+	ha2 := h(req.Method + ":" + uri)
+
+	// 服务器支持 qop=auth 时使用，否则按 RFC 2069 计算
+	qop := ""
+	for _, option := range strings.Split(params["qop"], ",") {
+		if strings.TrimSpace(option) == "auth" {
+			qop = "auth"
+		}
+	}
+
+	fields := []string{
+		fmt.Sprintf("username=%q", t.creds.Username),
+		fmt.Sprintf("realm=%q", realm),
+		fmt.Sprintf("nonce=%q", nonce),
+		fmt.Sprintf("uri=%q", uri),
+	}
+	if qop != "" {
+		response := h(ha1 + ":" + nonce + ":" + nc + ":" + cnonceHex + ":" + qop + ":" + ha2)
+		fields = append(fields, "qop="+qop, "nc="+nc, fmt.Sprintf("cnonce=%q", cnonceHex), fmt.Sprintf("response=%q", response))
+	} else {
+		fields = append(fields, fmt.Sprintf("response=%q", h(ha1+":"+nonce+":"+ha2)))
+	}
+	if algorithm := params["algorithm"]; algorithm != "" {
+		fields = append(fields, "algorithm="+algorithm)
+	}
+	if opaque, ok := params["opaque"]; ok {
+		fields = append(fields, fmt.Sprintf("opaque=%q", opaque))
+	}
+	return "Digest " + strings.Join(fields, ", "), true, nil
+}
+
+// CloseIdleConnections 关闭空闲连接
+func (t *digestTransport) CloseIdleConnections() {
+	t.base.CloseIdleConnections()
+}
+
+// digestHash 返回质询指定的摘要算法，未指定时为 MD5
+func digestHash(algorithm string) (func() hash.Hash, bool) {
+	switch strings.ToUpper(strings.TrimSuffix(strings.ToLower(algorithm), "-sess")) {
+	case "", "MD5":
+		return md5.New, true
+	case "SHA-256":
+		return sha256.New, true
+	default:
+		return nil, false
+	}
+}
+
+// parseParams 解析质询中逗号分隔的 key=value 参数，值可以带引号
+func parseParams(s string) map[string]string {
+	params := make(map[string]string)
+	for s != "" {
+		s = strings.TrimLeft(s, " ,")
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimLeft(rest, " ")
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			// 带引号的值可能包含逗号和转义字符
+			var b strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				b.WriteByte(rest[i])
+			}
+			value, s = b.String(), rest[min(i+1, len(rest)):]
+		} else {
+			value, s, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+		params[key] = value
+	}
+	return params
+}
@@ -0,0 +1,310 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+)
+
+// ntlmSignature NTLM 消息的签名
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// NTLM 协商标志 (MS-NLMP 2.2.2.5)
+const (
+	ntlmNegotiateUnicode            = 0x00000001
+	ntlmNegotiateOEM                = 0x00000002
+	ntlmRequestTarget               = 0x00000004
+	ntlmNegotiateNTLM               = 0x00000200
+	ntlmNegotiateAlwaysSign         = 0x00008000
+	ntlmNegotiateExtendedSec        = 0x00080000
+	ntlmNegotiateTargetInfo         = 0x00800000
+	ntlmNegotiate128                = 0x20000000
+	ntlmNegotiate56                 = 0x80000000
+	ntlmNegotiateFlags       uint32 = ntlmNegotiateUnicode | ntlmNegotiateOEM | ntlmRequestTarget | ntlmNegotiateNTLM |
+		ntlmNegotiateAlwaysSign | ntlmNegotiateExtendedSec | ntlmNegotiateTargetInfo | ntlmNegotiate128 | ntlmNegotiate56
+)
+
+// ntlmAvTimestamp 质询 TargetInfo 中服务器时间戳的 AvId
+const ntlmAvTimestamp = 7
+
+// ntlmTransport NTLM 认证
+// NTLM 认证的是连接而不是请求，握手的三条消息必须在同一个 TCP 连接上发送。
+// 因此每个连接使用单独的 Transport（每个主机最多一个连接），握手成功后该连接上的后续请求不再需要认证
+type ntlmTransport struct {
+	base  *http.Transport
+	creds Credentials
+	idle  chan *ntlmConn // 空闲的连接
+}
+
+// ntlmConn 一个 NTLM 连接
+type ntlmConn struct {
+	transport     *http.Transport
+	authenticated bool // 连接已完成握手
+}
+
+// newNTLMTransport 创建 NTLM Transport，空闲连接数与 base 的 MaxIdleConnsPerHost 一致
+func newNTLMTransport(base *http.Transport, creds Credentials) *ntlmTransport {
+	if domain, user, ok := strings.Cut(creds.Username, `\`); ok {
+		creds.Domain, creds.Username = domain, user
+	}
+	return &ntlmTransport{
+		base:  base,
+		creds: creds,
+		idle:  make(chan *ntlmConn, max(base.MaxIdleConnsPerHost, 1)),
+	}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *ntlmTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	conn := t.get()
+	resp, err := t.roundTrip(conn, req)
+	if err != nil {
+		conn.authenticated = false
+		t.put(conn)
+		return nil, err
+	}
+
+	// 响应体读完并关闭后连接才会空闲，此时再交给其他请求使用
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() { t.put(conn) }}
+	return resp, nil
+}
+
+// roundTrip 在指定连接上发送请求，连接尚未认证或认证失效时先完成握手
+func (t *ntlmTransport) roundTrip(conn *ntlmConn, req *http.Request) (*http.Response, error) {
+	if conn.authenticated {
+		r, err := rewind(req)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := conn.transport.RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := challenge(resp, "NTLM"); resp.StatusCode != http.StatusUnauthorized || !ok {
+			return resp, nil
+		}
+		// 服务器关闭了原来的连接，新连接需要重新握手
+		discard(resp)
+		conn.authenticated = false
+	}
+
+	// 第一步：发送协商消息
+	r, err := rewind(req)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Authorization", "NTLM "+base64.StdEncoding.EncodeToString(ntlmNegotiate()))
+	resp, err := conn.transport.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := challenge(resp, "NTLM")
+	if resp.StatusCode != http.StatusUnauthorized || !ok || value == "" {
+		// 服务器不需要 NTLM 认证
+		return resp, nil
+	}
+	message, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return resp, nil
+	}
+
+	// 第二步：根据服务器的质询计算应答，在同一连接上重发请求
+	authenticate, err := ntlmAuthenticate(t.creds, message)
+	if err != nil {
+		return resp, nil
+	}
+	r, err = rewind(req)
+	if err != nil {
+		return resp, nil
+	}
+	discard(resp)
+
+	r.Header.Set("Authorization", "NTLM "+base64.StdEncoding.EncodeToString(authenticate))
+	resp, err = conn.transport.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	conn.authenticated = resp.StatusCode != http.StatusUnauthorized
+	return resp, nil
+}
+
+// get 取出一个空闲连接，没有时新建
+func (t *ntlmTransport) get() *ntlmConn {
+	select {
+	case conn := <-t.idle:
+		return conn
+	default:
+	}
+
+	transport := t.base.Clone()
+	transport.MaxConnsPerHost = 1
+	transport.MaxIdleConnsPerHost = 1
+	// NTLM 不支持 HTTP/2
+	transport.Protocols = new(http.Protocols)
+	transport.Protocols.SetHTTP1(true)
+	return &ntlmConn{transport: transport}
+}
+
+// put 归还连接，空闲连接过多时关闭
+func (t *ntlmTransport) put(conn *ntlmConn) {
+	select {
+	case t.idle <- conn:
+	default:
+		conn.transport.CloseIdleConnections()
+	}
+}
+
+// CloseIdleConnections 关闭所有空闲连接
+func (t *ntlmTransport) CloseIdleConnections() {
+	t.base.CloseIdleConnections()
+	for {
+		select {
+		case conn := <-t.idle:
+			conn.transport.CloseIdleConnections()
+		default:
+			return
+		}
+	}
+}
+
+// releaseBody 关闭响应体时归还连接
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+// Close 关闭响应体并归还连接
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// ntlmNegotiate 生成协商消息 (Type 1)
+func ntlmNegotiate() []byte {
+	msg := make([]byte, 32)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 1)
+	binary.LittleEndian.PutUint32(msg[12:], ntlmNegotiateFlags)
+	return msg
+}
+
+// ntlmAuthenticate 根据服务器的质询消息 (Type 2) 生成 NTLMv2 认证消息 (Type 3)
+func ntlmAuthenticate(creds Credentials, challengeMsg []byte) ([]byte, error) {
+	if len(challengeMsg) < 32 || !bytes.Equal(challengeMsg[:8], ntlmSignature) || binary.LittleEndian.Uint32(challengeMsg[8:]) != 2 {
+		return nil, errors.New("无效的 NTLM 质询消息")
+	}
+	flags := binary.LittleEndian.Uint32(challengeMsg[20:])
+	serverChallenge := challengeMsg[24:32]
+
+	var targetInfo []byte
+	if len(challengeMsg) >= 48 {
+		length := int(binary.LittleEndian.Uint16(challengeMsg[40:]))
+		offset := int(binary.LittleEndian.Uint32(challengeMsg[44:]))
+		if offset+length <= len(challengeMsg) {
+			targetInfo = challengeMsg[offset : offset+length]
+		}
+	}
+
+	// 优先使用服务器提供的时间戳，避免时钟偏差导致认证失败
+	timestamp := ntlmTimestamp(targetInfo)
+	if timestamp == nil {
+		timestamp = binary.LittleEndian.AppendUint64(nil, uint64(time.Now().UnixNano()/100+116444736000000000))
+	}
+
+	clientChallenge := make([]byte, 8)
+	if _, err := rand.Read(clientChallenge); err != nil {
+		return nil, err
+	}
+
+	ntResponse, lmResponse := ntlmv2Response(ntowfv2(creds), serverChallenge, clientChallenge, timestamp, targetInfo)
+
+	domain := utf16le(creds.Domain)
+	user := utf16le(creds.Username)
+	workstation := utf16le("")
+
+	// 消息头 64 字节，之后依次是各字段的内容
+	msg := make([]byte, 64)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 3)
+	offset := len(msg)
+	for i, field := range [][]byte{lmResponse, ntResponse, domain, user, workstation, nil} {
+		pos := 12 + i*8
+		binary.LittleEndian.PutUint16(msg[pos:], uint16(len(field)))
+		binary.LittleEndian.PutUint16(msg[pos+2:], uint16(len(field)))
+		binary.LittleEndian.PutUint32(msg[pos+4:], uint32(offset))
+		offset += len(field)
+	}
+	binary.LittleEndian.PutUint32(msg[60:], flags&ntlmNegotiateFlags|ntlmNegotiateUnicode)
+	for _, field := range [][]byte{lmResponse, ntResponse, domain, user, workstation} {
+		msg = append(msg, field...)
+	}
+	return msg, nil
+}
+
+// ntowfv2 计算 NTOWFv2 = HMAC_MD5(MD4(UNICODE(Password)), UNICODE(Uppercase(User) + Domain))
+func ntowfv2(creds Credentials) []byte {
+	ntHash := md4(utf16le(creds.Password))
+	return hmacMD5(ntHash[:], utf16le(strings.ToUpper(creds.Username)+creds.Domain))
+}
+
+// ntlmv2Response 计算 NTLMv2 应答 (NTProofStr + temp) 和 LMv2 应答 (MS-NLMP 3.3.2)
+func ntlmv2Response(ntowf, serverChallenge, clientChallenge, timestamp, targetInfo []byte) (ntResponse, lmResponse []byte) {
+	temp := []byte{1, 1, 0, 0, 0, 0, 0, 0}
+	temp = append(temp, timestamp...)
+	temp = append(temp, clientChallenge...)
+	temp = append(temp, 0, 0, 0, 0)
+	temp = append(temp, targetInfo...)
+	temp = append(temp, 0, 0, 0, 0)
+	ntProof := hmacMD5(ntowf, append(append([]byte(nil), serverChallenge...), temp...))
+	ntResponse = append(ntProof, temp...)
+	lmResponse = append(hmacMD5(ntowf, append(append([]byte(nil), serverChallenge...), clientChallenge...)), clientChallenge...)
+	return ntResponse, lmResponse
+}
+
+// ntlmTimestamp 从质询的 TargetInfo 中取出服务器时间戳
+func ntlmTimestamp(targetInfo []byte) []byte {
+	for len(targetInfo) >= 4 {
+		id := binary.LittleEndian.Uint16(targetInfo)
+		length := int(binary.LittleEndian.Uint16(targetInfo[2:]))
+		if id == 0 || len(targetInfo) < 4+length {
+			return nil
+		}
+		if id == ntlmAvTimestamp && length == 8 {
+			return targetInfo[4:12]
+		}
+		targetInfo = targetInfo[4+length:]
+	}
+	return nil
+}
+
+// hmacMD5 计算 HMAC-MD5
+func hmacMD5(key, data []byte) []byte {
+	mac := hmac.New(md5.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// utf16le 将字符串编码为 UTF-16LE
+func utf16le(s string) []byte {
+	var b []byte
+	for _, r := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, r)
+	}
+	return b
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// MS-NLMP 4.2.4 中 NTLMv2 认证示例使用的参数
+var (
+	nlmpCreds           = Credentials{Username: "User", Domain: "Domain", Password: "Password"}
+	nlmpServerChallenge = mustHex("0123456789abcdef")
+	nlmpClientChallenge = mustHex("aaaaaaaaaaaaaaaa")
+	nlmpTimestamp       = make([]byte, 8)
+	nlmpTargetInfo      = mustHex("02000c0044006f006d00610069006e0001000c0053006500720076006500720000000000")
+	nlmpNTOWFv2         = mustHex("0c868a403bfd7a93a3001ef22ef02e3f")
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestMD4(t *testing.T) {
+	// RFC 1320 附录 A.5 的测试向量
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"a", "bde52cb31de33e46245e05fbdbd6fb24"},
+		{"abc", "a448017aaf21d8525fc10ae87aa6729d"},
+		{"message digest", "d9130a8164549fe818874806e1c7014b"},
+		{"abcdefghijklmnopqrstuvwxyz", "d79e1c308aa5bbcdeea8ed63df412da9"},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", "043f8582f241db351ce627e153e7f0e4"},
+		{strings.Repeat("1234567890", 8), "e33b4ddc9c38f2199c3e7b164fcc0536"},
+	}
+
+	for _, tt := range tests {
+		got := md4([]byte(tt.input))
+		if hex.EncodeToString(got[:]) != tt.want {
+			t.Errorf("md4(%q) = %x, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestNTOWF(t *testing.T) {
+	// MS-NLMP 4.2.2.1.2 NTOWFv1 即密码的 MD4
+	ntHash := md4(utf16le(nlmpCreds.Password))
+	if got, want := hex.EncodeToString(ntHash[:]), "a4f49c406510bdcab6824ee7c30fd852"; got != want {
+		t.Errorf("NTOWFv1 = %s, want %s", got, want)
+	}
+	// MS-NLMP 4.2.4.1.1
+	if got := ntowfv2(nlmpCreds); !bytes.Equal(got, nlmpNTOWFv2) {
+		t.Errorf("NTOWFv2 = %x, want %x", got, nlmpNTOWFv2)
+	}
+}
+
+func TestNTLMv2Response(t *testing.T) {
+	nt, lm := ntlmv2Response(nlmpNTOWFv2, nlmpServerChallenge, nlmpClientChallenge, nlmpTimestamp, nlmpTargetInfo)
+
+	// MS-NLMP 4.2.4.2.1 LMv2 应答
+	if want := mustHex("86c35097ac9cec102554764a57cccc19aaaaaaaaaaaaaaaa"); !bytes.Equal(lm, want) {
+		t.Errorf("LMv2 = %x, want %x", lm, want)
+	}
+	// MS-NLMP 4.2.4.2.2 NTProofStr
+	if want := mustHex("68cd0ab851e51c96aabc927bebef6a1c"); !bytes.Equal(nt[:16], want) {
+		t.Errorf("NTProofStr = %x, want %x", nt[:16], want)
+	}
+	temp := append(append(append(mustHex("0101000000000000"), nlmpTimestamp...), nlmpClientChallenge...), 0, 0, 0, 0)
+	temp = append(append(temp, nlmpTargetInfo...), 0, 0, 0, 0)
+	if !bytes.Equal(nt[16:], temp) {
+		t.Errorf("temp = %x, want %x", nt[16:], temp)
+	}
+}
+
+// challengeMessage 构造带 TargetInfo 的质询消息 (Type 2)
+func challengeMessage(targetInfo []byte) []byte {
+	msg := make([]byte, 48)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 2)
+	binary.LittleEndian.PutUint32(msg[20:], ntlmNegotiateFlags)
+	copy(msg[24:], nlmpServerChallenge)
+	binary.LittleEndian.PutUint16(msg[40:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint16(msg[42:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint32(msg[44:], uint32(len(msg)))
+	return append(msg, targetInfo...)
+}
+
+// messageField 取出认证消息 (Type 3) 中第 index 个字段的内容
+func messageField(msg []byte, index int) []byte {
+	pos := 12 + index*8
+	length := int(binary.LittleEndian.Uint16(msg[pos:]))
+	offset := int(binary.LittleEndian.Uint32(msg[pos+4:]))
+	return msg[offset : offset+length]
+}
+
+func TestNTLMAuthenticate(t *testing.T) {
+	// 服务器提供时间戳时应答中使用该时间戳
+	serverTime := mustHex("0090d336b734c301")
+	targetInfo := append(append(mustHex("07000800"), serverTime...), nlmpTargetInfo...)
+
+	msg, err := ntlmAuthenticate(nlmpCreds, challengeMessage(targetInfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(msg[:8], ntlmSignature) || binary.LittleEndian.Uint32(msg[8:]) != 3 {
+		t.Fatalf("不是认证消息: %x", msg[:12])
+	}
+
+	nt := messageField(msg, 1)
+	clientChallenge := nt[32:40]
+	wantNT, wantLM := ntlmv2Response(nlmpNTOWFv2, nlmpServerChallenge, clientChallenge, serverTime, targetInfo)
+	if !bytes.Equal(nt, wantNT) {
+		t.Errorf("NTLMv2 应答 = %x, want %x", nt, wantNT)
+	}
+	if lm := messageField(msg, 0); !bytes.Equal(lm, wantLM) {
+		t.Errorf("LMv2 应答 = %x, want %x", lm, wantLM)
+	}
+	if got := messageField(msg, 2); !bytes.Equal(got, utf16le("Domain")) {
+		t.Errorf("域 = %x, want %x", got, utf16le("Domain"))
+	}
+	if got := messageField(msg, 3); !bytes.Equal(got, utf16le("User")) {
+		t.Errorf("用户名 = %x, want %x", got, utf16le("User"))
+	}
+}
+
+func TestNTLMAuthenticateInvalid(t *testing.T) {
+	wrongType := challengeMessage(nil)
+	binary.LittleEndian.PutUint32(wrongType[8:], 1)
+
+	tests := []struct {
+		name string
+		msg  []byte
+	}{
+		{"消息过短", ntlmSignature},
+		{"签名错误", append([]byte("NTLMSSX\x00"), make([]byte, 40)...)},
+		{"消息类型错误", wrongType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ntlmAuthenticate(nlmpCreds, tt.msg); err == nil {
+				t.Error("ntlmAuthenticate() 没有返回错误")
+			}
+		})
+	}
+}
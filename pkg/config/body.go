@@ -0,0 +1,143 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// loadDataFile 读取 DataFile 指定的请求体
+func (c *Config) loadDataFile() error {
+	if c.DataFile == "" {
+		return nil
+	}
+	if c.Data != "" {
+		return fmt.Errorf("请求体 (-d) 和请求体文件 (-data-file) 只能指定一种")
+	}
+
+	data, err := os.ReadFile(c.DataFile)
+	if err != nil {
+		return fmt.Errorf("读取请求体文件失败: %w", err)
+	}
+	c.Data = string(data)
+	return nil
+}
+
+// parseJSONValue 解析 -json 参数的值：合法的JSON（数字、布尔、null、对象、数组、带引号的字符串）按原类型保留，否则视为字符串
+func parseJSONValue(value string) any {
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err == nil {
+		return v
+	}
+	return value
+}
+
+// RequestBody 返回编码后的请求体，replace 不为 nil 时先对模板中的字符串进行替换
+// 表单和JSON字段在替换之后才编码，代入的词条会被正确转义
+func (c *Config) RequestBody(replace func(string) string) string {
+	if replace == nil {
+		replace = func(s string) string { return s }
+	}
+
+	switch {
+	case len(c.Form) > 0:
+		values := make(url.Values, len(c.Form))
+		for key, value := range c.Form {
+			values.Add(replace(key), replace(value))
+		}
+		return values.Encode()
+	case len(c.JSON) > 0:
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(replaceJSON(c.JSON, replace)); err != nil {
+			return ""
+		}
+		return strings.TrimSuffix(buf.String(), "\n")
+	default:
+		return replace(c.Data)
+	}
+}
+
+// replaceJSON 递归替换JSON中的键和字符串值
+func replaceJSON(v any, replace func(string) string) any {
+	switch v := v.(type) {
+	case string:
+		return replace(v)
+	case map[string]any:
+		replaced := make(map[string]any, len(v))
+		for key, value := range v {
+			replaced[replace(key)] = replaceJSON(value, replace)
+		}
+		return replaced
+	case []any:
+		replaced := make([]any, len(v))
+		for i, value := range v {
+			replaced[i] = replaceJSON(value, replace)
+		}
+		return replaced
+	default:
+		return v
+	}
+}
+
+// RequestContentType 返回请求体的 Content-Type：优先使用配置的值，否则按请求体的来源和内容推断
+func (c *Config) RequestContentType() string {
+	switch {
+	case c.ContentType != "":
+		return c.ContentType
+	case len(c.Form) > 0:
+		return "application/x-www-form-urlencoded"
+	case len(c.JSON) > 0:
+		return "application/json"
+	case c.Data == "":
+		return ""
+	case json.Valid([]byte(c.Data)) || strings.HasPrefix(strings.TrimSpace(c.Data), "{"):
+		// 模板中未加引号的关键字会使JSON暂时不合法，因此以 { 开头也视为JSON
+		return "application/json"
+	default:
+		return "application/x-www-form-urlencoded"
+	}
+}
+
+// Body 返回编码后的登录请求体
+func (l LoginConfig) Body() string {
+	if len(l.Form) > 0 {
+		values := make(url.Values, len(l.Form))
+		for key, value := range l.Form {
+			values.Add(key, value)
+		}
+		return values.Encode()
+	}
+	return l.Data
+}
+
+// RequestContentType 返回登录请求体的 Content-Type，推断规则与扫描请求相同
+func (l LoginConfig) RequestContentType() string {
+	c := Config{ContentType: l.ContentType, Form: l.Form, Data: l.Data}
+	return c.RequestContentType()
+}
+
+// validate 检查登录配置，正则表达式在这里编译一次以便尽早报错
+func (l LoginConfig) validate() error {
+	if !l.Enabled() {
+		return nil
+	}
+	if l.Method == "" {
+		return fmt.Errorf("登录请求方法不能为空")
+	}
+	for name, pattern := range map[string]string{
+		"登录成功":    l.SuccessRegex,
+		"会话失效重定向": l.LostLocation,
+		"会话失效":    l.LostRegex,
+	} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("编译%s正则表达式失败: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequestBody(t *testing.T) {
+	fuzz := strings.NewReplacer("FUZZ", "a&b=\"c\"").Replace
+
+	tests := []struct {
+		name    string
+		config  Config
+		replace func(string) string
+		want    string
+	}{
+		{"没有请求体", Config{}, nil, ""},
+		{"原始请求体", Config{Data: "user=FUZZ&pass=x"}, nil, "user=FUZZ&pass=x"},
+		{"原始请求体中的关键字", Config{Data: "user=FUZZ"}, fuzz, `user=a&b="c"`},
+		{"表单按键排序编码", Config{Form: map[string]string{"pass": "p w", "user": "admin"}}, nil, "pass=p+w&user=admin"},
+		{"表单替换后再编码", Config{Form: map[string]string{"user": "FUZZ"}}, fuzz, "user=a%26b%3D%22c%22"},
+		{"表单的键", Config{Form: map[string]string{"FUZZ": "1"}}, fuzz, "a%26b%3D%22c%22=1"},
+		{"表单优先于原始请求体", Config{Form: map[string]string{"a": "1"}, Data: "ignored"}, nil, "a=1"},
+		{
+			"JSON 保留值的类型",
+			Config{JSON: map[string]any{"id": float64(1), "admin": true, "tags": []any{"a", nil}}},
+			nil,
+			`{"admin":true,"id":1,"tags":["a",null]}`,
+		},
+		{
+			"JSON 替换后再转义",
+			Config{JSON: map[string]any{"user": "FUZZ", "nested": map[string]any{"FUZZ": []any{"FUZZ", float64(2)}}}},
+			fuzz,
+			`{"nested":{"a&b=\"c\"":["a&b=\"c\"",2]},"user":"a&b=\"c\""}`,
+		},
+		{"JSON 优先于原始请求体", Config{JSON: map[string]any{"a": "<b>"}, Data: "ignored"}, nil, `{"a":"<b>"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.RequestBody(tt.replace); got != tt.want {
+				t.Errorf("RequestBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestContentType(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"没有请求体", Config{}, ""},
+		{"配置的值优先", Config{ContentType: "text/xml", Form: map[string]string{"a": "1"}}, "text/xml"},
+		{"表单", Config{Form: map[string]string{"a": "1"}}, "application/x-www-form-urlencoded"},
+		{"JSON", Config{JSON: map[string]any{"a": "1"}}, "application/json"},
+		{"合法的JSON请求体", Config{Data: `["a"]`}, "application/json"},
+		{"含未加引号关键字的JSON模板", Config{Data: `{"id": FUZZ}`}, "application/json"},
+		{"以空白开头的JSON模板", Config{Data: " \n{\"id\": FUZZ}"}, "application/json"},
+		{"表单请求体", Config{Data: "user=FUZZ"}, "application/x-www-form-urlencoded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.RequestContentType(); got != tt.want {
+				t.Errorf("RequestContentType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
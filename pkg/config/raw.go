@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// rawSkipHeaders 原始请求中不应原样转发的请求头，由HTTP客户端自行处理
+var rawSkipHeaders = map[string]bool{
+	"Content-Length":    true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+	"Accept-Encoding":   true,
+	"Proxy-Connection":  true,
+}
+
+// loadRawRequest 读取 Raw 指定的原始HTTP请求，作为所有请求的模板
+// 请求行中的方法和路径、请求头和请求体覆盖对应的配置；协议和主机取自 -u（只使用其协议和主机部分），否则取 RawScheme 和 Host 请求头
+// 模板中没有关键字时，请求路径中的查询字符串会被去掉
+func (c *Config) loadRawRequest(target string) error {
+	if c.Raw == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.Raw)
+	if err != nil {
+		return fmt.Errorf("读取原始请求失败: %w", err)
+	}
+
+	// 请求头与请求体之间以空行分隔，兼容 \r\n 和 \n 两种换行
+	head, body := data, []byte(nil)
+	if i := bytes.Index(data, []byte("\r\n\r\n")); i >= 0 {
+		head, body = data[:i], data[i+4:]
+	} else if i := bytes.Index(data, []byte("\n\n")); i >= 0 {
+		head, body = data[:i], data[i+2:]
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(head), "\r\n", "\n"), "\n")
+	requestLine := strings.Fields(lines[0])
+	if len(requestLine) < 2 {
+		return fmt.Errorf("无效的请求行: %s", lines[0])
+	}
+	method, requestURI := requestLine[0], requestLine[1]
+
+	headers := make(map[string]string)
+	host := ""
+	for _, line := range lines[1:] {
+		// 跳过空行和从 HTTP/2 请求中复制出的伪首部
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("无效的请求头: %s", line)
+		}
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		switch {
+		case name == "Host":
+			host = value
+		case rawSkipHeaders[name]:
+		default:
+			headers[name] = value
+		}
+	}
+
+	// 请求行是完整URL时（代理格式）直接使用
+	if strings.HasPrefix(requestURI, "http://") || strings.HasPrefix(requestURI, "https://") {
+		if u, err := url.Parse(requestURI); err == nil {
+			if host == "" {
+				host = u.Host
+			}
+			if target == "" {
+				target = u.Scheme + "://" + u.Host
+			}
+			requestURI = strings.TrimPrefix(requestURI, u.Scheme+"://"+u.Host)
+		}
+	}
+
+	// -u 指定了连接地址时，原始请求中不同的 Host 请求头原样保留
+	origin := ""
+	if target != "" {
+		u, err := url.Parse(target)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("无效的目标URL: %s", target)
+		}
+		origin = u.Scheme + "://" + u.Host
+		if host != "" && !strings.EqualFold(host, u.Host) {
+			headers["Host"] = host
+		}
+	} else {
+		if host == "" {
+			return fmt.Errorf("原始请求中没有 Host 请求头，请使用 -u 指定目标")
+		}
+		origin = c.RawScheme + "://" + host
+	}
+
+	if !strings.HasPrefix(requestURI, "/") {
+		requestURI = "/" + requestURI
+	}
+	c.Target = origin + requestURI
+	c.Scanner.Methods = []string{method}
+	if c.Headers == nil {
+		c.Headers = make(map[string]string)
+	}
+	for name, value := range headers {
+		c.Headers[name] = value
+	}
+	if len(body) > 0 {
+		c.Data = string(body)
+	}
+
+	// 没有 FUZZ 标记时词条追加到路径之后，去掉查询字符串，避免生成 /search?q=1/admin 这样的URL
+	if !c.FuzzMode() {
+		if path, _, ok := strings.Cut(requestURI, "?"); ok {
+			c.Target = origin + path
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadRawRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		target      string
+		wantTarget  string
+		wantMethod  string
+		wantHeaders map[string]string
+		wantData    string
+		wantErr     bool
+	}{
+		{
+			name:        "协议和主机取自 Host 请求头",
+			raw:         "GET /api/ HTTP/1.1\r\nHost: example.com\r\nX-Token: abc\r\nContent-Length: 0\r\nConnection: close\r\n\r\n",
+			wantTarget:  "https://example.com/api/",
+			wantMethod:  "GET",
+			wantHeaders: map[string]string{"X-Token": "abc"},
+		},
+		{
+			name:        "请求体",
+			raw:         "POST /login HTTP/1.1\nHost: example.com\ncontent-type: application/json\n\n{\"user\":\"FUZZ\"}",
+			wantTarget:  "https://example.com/login",
+			wantMethod:  "POST",
+			wantHeaders: map[string]string{"Content-Type": "application/json"},
+			wantData:    `{"user":"FUZZ"}`,
+		},
+		{
+			name:        "-u 指定连接地址时保留 Host 请求头",
+			raw:         "GET /app HTTP/1.1\nHost: internal.example.com\n\n",
+			target:      "http://10.0.0.5:8080/ignored",
+			wantTarget:  "http://10.0.0.5:8080/app",
+			wantMethod:  "GET",
+			wantHeaders: map[string]string{"Host": "internal.example.com"},
+		},
+		{
+			name:        "代理格式的请求行",
+			raw:         "GET http://example.com:8080/a HTTP/1.1\n\n",
+			wantTarget:  "http://example.com:8080/a",
+			wantMethod:  "GET",
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:        "忽略 HTTP/2 伪首部",
+			raw:         "GET /a HTTP/2\n:authority: example.com\nHost: example.com\n\n",
+			wantTarget:  "https://example.com/a",
+			wantMethod:  "GET",
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:        "没有 FUZZ 标记时去掉查询字符串",
+			raw:         "GET /search?q=1 HTTP/1.1\nHost: example.com\n\n",
+			wantTarget:  "https://example.com/search",
+			wantMethod:  "GET",
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:        "有 FUZZ 标记时保留查询字符串",
+			raw:         "GET /search?q=FUZZ HTTP/1.1\nHost: example.com\n\n",
+			wantTarget:  "https://example.com/search?q=FUZZ",
+			wantMethod:  "GET",
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:    "没有 Host 请求头也没有 -u",
+			raw:     "GET / HTTP/1.1\n\n",
+			wantErr: true,
+		},
+		{
+			name:    "无效的请求行",
+			raw:     "GET\nHost: example.com\n\n",
+			wantErr: true,
+		},
+		{
+			name:    "无效的请求头",
+			raw:     "GET / HTTP/1.1\nHost example.com\n\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "request.txt")
+			if err := os.WriteFile(path, []byte(tt.raw), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			c := &Config{Raw: path, RawScheme: "https"}
+			c.Scanner.Methods = []string{"PUT"}
+			err := c.loadRawRequest(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadRawRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if c.Target != tt.wantTarget {
+				t.Errorf("Target = %q, want %q", c.Target, tt.wantTarget)
+			}
+			if !slices.Equal(c.Scanner.Methods, []string{tt.wantMethod}) {
+				t.Errorf("Methods = %q, want [%q]", c.Scanner.Methods, tt.wantMethod)
+			}
+			if !maps.Equal(c.Headers, tt.wantHeaders) {
+				t.Errorf("Headers = %v, want %v", c.Headers, tt.wantHeaders)
+			}
+			if c.Data != tt.wantData {
+				t.Errorf("Data = %q, want %q", c.Data, tt.wantData)
+			}
+		})
+	}
+}
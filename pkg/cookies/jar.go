@@ -0,0 +1,122 @@
+package cookies
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Jar 所有工作线程和目标共用的 Cookie 罐
+// 由 net/http/cookiejar 负责匹配和发送，同时记录收到的每个 Cookie，以便扫描结束后导出
+type Jar struct {
+	jar *cookiejar.Jar
+
+	mu      sync.Mutex
+	entries map[string]*Entry // 键为 域名;路径;名称
+}
+
+// Entry 一条 Cookie 记录，字段与 Netscape Cookie 文件一一对应
+type Entry struct {
+	Domain            string
+	IncludeSubdomains bool
+	Path              string
+	Secure            bool
+	HttpOnly          bool
+	Expires           time.Time // 零值表示会话 Cookie
+	Name              string
+	Value             string
+}
+
+// New 创建 Cookie 罐
+func New() *Jar {
+	jar, _ := cookiejar.New(nil)
+	return &Jar{jar: jar, entries: make(map[string]*Entry)}
+}
+
+// SetCookies 实现 http.CookieJar，保存响应中的 Cookie
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	host := strings.ToLower(u.Hostname())
+	for _, c := range cookies {
+		entry := &Entry{
+			Domain:   host,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			Name:     c.Name,
+			Value:    c.Value,
+		}
+
+		// 带 Domain 属性的 Cookie 对子域名同样有效，域名必须与请求的主机匹配，否则会被 cookiejar 拒绝
+		if domain := strings.ToLower(strings.TrimPrefix(c.Domain, ".")); domain != "" {
+			if host != domain && !strings.HasSuffix(host, "."+domain) {
+				continue
+			}
+			entry.Domain, entry.IncludeSubdomains = domain, true
+		}
+		if !strings.HasPrefix(entry.Path, "/") {
+			entry.Path = defaultPath(u.Path)
+		}
+
+		key := entry.Domain + ";" + entry.Path + ";" + entry.Name
+		switch {
+		case c.MaxAge < 0, c.MaxAge == 0 && !c.Expires.IsZero() && c.Expires.Before(time.Now()):
+			// 服务器删除了 Cookie
+			delete(j.entries, key)
+			continue
+		case c.MaxAge > 0:
+			entry.Expires = time.Now().Add(time.Duration(c.MaxAge) * time.Second)
+		default:
+			entry.Expires = c.Expires
+		}
+		j.entries[key] = entry
+	}
+}
+
+// Cookies 实现 http.CookieJar，返回发送给 u 的 Cookie
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// Set 将 "name=value; name2=value2" 格式的 Cookie 加入罐中，作为发送给 target 的初始 Cookie
+func (j *Jar) Set(target *url.URL, value string) error {
+	cookies, err := http.ParseCookie(value)
+	if err != nil {
+		return err
+	}
+	for _, c := range cookies {
+		c.Path = "/"
+	}
+	j.SetCookies(target, cookies)
+	return nil
+}
+
+// Entries 返回罐中所有未过期的 Cookie
+func (j *Jar) Entries() []*Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	entries := make([]*Entry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		if entry.Expires.IsZero() || entry.Expires.After(now) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// defaultPath 计算没有 Path 属性的 Cookie 的默认路径 (RFC 6265 5.1.4)
+func defaultPath(p string) string {
+	if !strings.HasPrefix(p, "/") || strings.Count(p, "/") == 1 {
+		return "/"
+	}
+	return path.Dir(p)
+}
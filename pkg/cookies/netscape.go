@@ -0,0 +1,122 @@
+package cookies
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpOnlyPrefix curl 用该前缀标记 HttpOnly 的 Cookie
+const httpOnlyPrefix = "#HttpOnly_"
+
+// Load 从 Netscape/curl 格式的 Cookie 文件导入 Cookie
+// 每行依次为 域名、是否包含子域名、路径、是否仅 HTTPS、过期时间（Unix 秒，0 为会话 Cookie）、名称、值，以制表符分隔
+func (j *Jar) Load(filename string) (int, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	count := 0
+	lineNum := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := strings.HasPrefix(line, httpOnlyPrefix)
+		line = strings.TrimPrefix(line, httpOnlyPrefix)
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) == 6 {
+			// 值为空时部分工具会省略最后一个字段
+			fields = append(fields, "")
+		}
+		if len(fields) != 7 {
+			return count, fmt.Errorf("%s:%d: 字段数量错误", filename, lineNum)
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return count, fmt.Errorf("%s:%d: 无效的过期时间: %s", filename, lineNum, fields[4])
+		}
+
+		domain := strings.TrimPrefix(fields[0], ".")
+		cookie := &http.Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+		}
+		if strings.EqualFold(fields[1], "TRUE") {
+			cookie.Domain = domain
+		}
+		if expires > 0 {
+			if time.Unix(expires, 0).Before(time.Now()) {
+				continue
+			}
+			cookie.Expires = time.Unix(expires, 0)
+		}
+
+		scheme := "http"
+		if cookie.Secure {
+			scheme = "https"
+		}
+		j.SetCookies(&url.URL{Scheme: scheme, Host: domain, Path: cookie.Path}, []*http.Cookie{cookie})
+		count++
+	}
+	return count, scanner.Err()
+}
+
+// Save 将罐中的 Cookie 导出为 Netscape 格式，可以直接交给 curl 或再次导入
+func (j *Jar) Save(filename string) error {
+	entries := j.Entries()
+	sort.Slice(entries, func(a, b int) bool {
+		if entries[a].Domain != entries[b].Domain {
+			return entries[a].Domain < entries[b].Domain
+		}
+		if entries[a].Path != entries[b].Path {
+			return entries[a].Path < entries[b].Path
+		}
+		return entries[a].Name < entries[b].Name
+	})
+
+	var b strings.Builder
+	b.WriteString("# Netscape HTTP Cookie File\n")
+	b.WriteString("# 由 dirsearch-go 导出\n\n")
+	for _, entry := range entries {
+		domain := entry.Domain
+		if entry.IncludeSubdomains {
+			domain = "." + domain
+		}
+		if entry.HttpOnly {
+			domain = httpOnlyPrefix + domain
+		}
+		var expires int64
+		if !entry.Expires.IsZero() {
+			expires = entry.Expires.Unix()
+		}
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, netscapeBool(entry.IncludeSubdomains), entry.Path, netscapeBool(entry.Secure),
+			expires, entry.Name, entry.Value)
+	}
+	return os.WriteFile(filename, []byte(b.String()), 0644)
+}
+
+// netscapeBool Netscape 格式中的布尔值
+func netscapeBool(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
@@ -0,0 +1,159 @@
+package cookies
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile 在临时目录中写入 Cookie 文件
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// sortedEntries 按域名、路径和名称排序返回罐中的 Cookie
+func sortedEntries(j *Jar) []Entry {
+	var entries []Entry
+	for _, entry := range j.Entries() {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].Domain+entries[a].Path+entries[a].Name < entries[b].Domain+entries[b].Path+entries[b].Name
+	})
+	return entries
+}
+
+// cookieNames 返回发送给 rawURL 的 Cookie 名称
+func cookieNames(j *Jar, rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		panic(err)
+	}
+	var names []string
+	for _, c := range j.Cookies(u) {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func TestLoad(t *testing.T) {
+	expires := time.Now().Add(time.Hour).Unix()
+	filename := writeFile(t, "# Netscape HTTP Cookie File\n\n"+
+		".example.com\tTRUE\t/\tFALSE\t0\tsid\tabc\r\n"+
+		fmt.Sprintf("#HttpOnly_admin.example.com\tFALSE\t/admin\tTRUE\t%d\ttoken\txyz\n", expires)+
+		"example.com\tFALSE\t/\tFALSE\t0\tempty\n"+
+		"example.com\tFALSE\t/\tFALSE\t1000\told\tgone\n")
+
+	j := New()
+	n, err := j.Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("Load() = %d, want 3", n)
+	}
+
+	want := []Entry{
+		{Domain: "admin.example.com", Path: "/admin", Secure: true, HttpOnly: true, Expires: time.Unix(expires, 0), Name: "token", Value: "xyz"},
+		{Domain: "example.com", Path: "/", Name: "empty"},
+		{Domain: "example.com", IncludeSubdomains: true, Path: "/", Name: "sid", Value: "abc"},
+	}
+	got := sortedEntries(j)
+	if len(got) != len(want) {
+		t.Fatalf("Entries() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Entries()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://example.com/", "empty,sid"},
+		{"http://www.example.com/", "sid"},
+		{"https://admin.example.com/admin/users", "sid,token"},
+		{"http://admin.example.com/admin/users", "sid"},
+		{"https://admin.example.com/", "sid"},
+	}
+	for _, tt := range tests {
+		if got := cookieNames(j, tt.url); got != tt.want {
+			t.Errorf("Cookies(%s) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"字段数量错误", "example.com\tFALSE\t/\tFALSE\t0\n"},
+		{"无效的过期时间", "example.com\tFALSE\t/\tFALSE\tnever\tsid\tabc\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New().Load(writeFile(t, tt.content)); err == nil {
+				t.Error("Load() 没有返回错误")
+			}
+		})
+	}
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	expires := time.Now().Add(time.Hour).Unix()
+	source := writeFile(t, ".example.com\tTRUE\t/\tFALSE\t0\tsid\tabc\n"+
+		fmt.Sprintf("#HttpOnly_admin.example.com\tFALSE\t/admin\tTRUE\t%d\ttoken\txyz\n", expires)+
+		"example.com\tFALSE\t/\tFALSE\t0\tempty\t\n")
+
+	j := New()
+	if _, err := j.Load(source); err != nil {
+		t.Fatal(err)
+	}
+	saved := filepath.Join(t.TempDir(), "saved.txt")
+	if err := j.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		fmt.Sprintf("#HttpOnly_admin.example.com\tFALSE\t/admin\tTRUE\t%d\ttoken\txyz\n", expires),
+		"example.com\tFALSE\t/\tFALSE\t0\tempty\t\n",
+		".example.com\tTRUE\t/\tFALSE\t0\tsid\tabc\n",
+	} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("导出的文件中没有 %q:\n%s", line, data)
+		}
+	}
+
+	reloaded := New()
+	if _, err := reloaded.Load(saved); err != nil {
+		t.Fatal(err)
+	}
+	before, after := sortedEntries(j), sortedEntries(reloaded)
+	if len(before) != len(after) {
+		t.Fatalf("重新导入后 %+v, want %+v", after, before)
+	}
+	for i := range before {
+		if before[i] != after[i] {
+			t.Errorf("重新导入后 %+v, want %+v", after[i], before[i])
+		}
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"dirsearch-go/pkg/scanner"
@@ -63,22 +66,43 @@ func NewConsoleWriter(verbose bool) *ConsoleWriter {
 
 // Write 写入结果到控制台
 func (w *ConsoleWriter) Write(result *scanner.Result) error {
+	if result.Calibration {
+		if w.verbose {
+			fmt.Fprintf(os.Stdout, "[CALIBRATION] [%d] %s%s [%d bytes] [%d words] [%d lines]\n",
+				result.StatusCode, result.URL, formatHost(result.Host), result.Size, result.Words, result.Lines)
+		}
+		return nil
+	}
+
 	if result.Error != "" {
 		if w.verbose {
 			// 错误信息输出到 stdout，保持一致性
-			fmt.Fprintf(os.Stdout, "[ERROR] %s: %s\n", result.URL, result.Error)
+			fmt.Fprintf(os.Stdout, "[ERROR] %s%s: %s\n", result.URL, formatHost(result.Host), result.Error)
 		}
 		return nil
 	}
 
 	var output string
 	if w.verbose {
-		output = fmt.Sprintf("[%d] %s [%s] [%d bytes] [%s]",
-			result.StatusCode, result.URL, result.Method, result.Size,
+		output = fmt.Sprintf("[%d] %s [%s] [%s] [%d bytes] [%s]",
+			result.StatusCode, result.URL, result.Method, result.Protocol, result.Size,
 			result.Timestamp.Format("15:04:05"))
+		if result.Source != "" && result.Source != scanner.SourceWordlist && result.DerivedFrom == "" {
+			output += fmt.Sprintf(" [%s]", result.Source)
+		}
+		if result.UserAgent != "" {
+			output += fmt.Sprintf(" [UA: %s]", result.UserAgent)
+		}
 	} else {
 		output = fmt.Sprintf("[%d] %s", result.StatusCode, result.URL)
 	}
+	output += formatHost(result.Host)
+	if len(result.Payload) > 0 {
+		output += " " + formatPayload(result.Payload)
+	}
+	if result.DerivedFrom != "" {
+		output += fmt.Sprintf(" [%s of %s]", result.Source, result.DerivedFrom)
+	}
 
 	// 确保所有输出都到 stdout
 	switch {
@@ -97,6 +121,29 @@ func (w *ConsoleWriter) Write(result *scanner.Result) error {
 	return nil
 }
 
+// formatHost 格式化虚拟主机扫描的 Host，例如 [Host: admin.example.com]，没有时返回空字符串
+func formatHost(host string) string {
+	if host == "" {
+		return ""
+	}
+	return " [Host: " + host + "]"
+}
+
+// formatPayload 按关键字排序格式化 FUZZ 模式的词条，例如 [FUZZ: admin]
+func formatPayload(payload map[string]string) string {
+	keys := make([]string, 0, len(payload))
+	for key := range payload {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+": "+payload[key])
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
 // Close 关闭控制台输出器
 func (w *ConsoleWriter) Close() error {
 	return nil
@@ -188,6 +235,8 @@ func (w *BufferedJSONWriter) Flush() error {
 	}
 	defer file.Close()
 
+	groupByTarget(w.results)
+
 	// 写入JSON数组
 	if _, err := file.WriteString("[\n"); err != nil {
 		return fmt.Errorf("写入JSON开始失败: %w", err)
@@ -225,6 +274,36 @@ func (w *BufferedJSONWriter) Close() error {
 	return w.Flush()
 }
 
+// csvHeader CSV输出的表头
+var csvHeader = []string{"URL", "StatusCode", "Size", "Method", "Depth", "Timestamp", "Error", "Source", "Payload", "DerivedFrom", "Target", "Protocol", "Host"}
+
+// csvRecord 将结果转换为CSV数据行
+func csvRecord(result *scanner.Result) []string {
+	return []string{
+		result.URL,
+		strconv.Itoa(result.StatusCode),
+		strconv.FormatInt(result.Size, 10),
+		result.Method,
+		strconv.Itoa(result.Depth),
+		result.Timestamp.Format(time.RFC3339),
+		result.Error,
+		result.Source,
+		payloadField(result.Payload),
+		result.DerivedFrom,
+		result.Target,
+		result.Protocol,
+		result.Host,
+	}
+}
+
+// payloadField 将词条格式化为CSV字段
+func payloadField(payload map[string]string) string {
+	if len(payload) == 0 {
+		return ""
+	}
+	return strings.Trim(formatPayload(payload), "[]")
+}
+
 // NewCSVWriter 创建CSV输出器
 func NewCSVWriter(filename string) (*CSVWriter, error) {
 	file, err := os.Create(filename)
@@ -241,25 +320,21 @@ func NewCSVWriter(filename string) (*CSVWriter, error) {
 
 // Write 写入结果到CSV文件
 func (w *CSVWriter) Write(result *scanner.Result) error {
+	// 校准探测结果只记录在JSON输出中
+	if result.Calibration {
+		return nil
+	}
+
 	// 写入表头
 	if !w.header {
-		header := []string{"URL", "StatusCode", "Size", "Method", "Depth", "Timestamp", "Error"}
-		if err := w.writer.Write(header); err != nil {
+		if err := w.writer.Write(csvHeader); err != nil {
 			return fmt.Errorf("写入CSV表头失败: %w", err)
 		}
 		w.header = true
 	}
 
 	// 写入数据行
-	record := []string{
-		result.URL,
-		strconv.Itoa(result.StatusCode),
-		strconv.FormatInt(result.Size, 10),
-		result.Method,
-		strconv.Itoa(result.Depth),
-		result.Timestamp.Format(time.RFC3339),
-		result.Error,
-	}
+	record := csvRecord(result)
 
 	if err := w.writer.Write(record); err != nil {
 		return fmt.Errorf("写入CSV数据失败: %w", err)
@@ -312,27 +387,24 @@ func (w *BufferedCSVWriter) Flush() error {
 	}
 	defer file.Close()
 
+	groupByTarget(w.results)
+
 	writer := csv.NewWriter(file)
 	defer writer.Flush()
 
 	// 写入表头
-	header := []string{"URL", "StatusCode", "Size", "Method", "Depth", "Timestamp", "Error"}
-	if err := writer.Write(header); err != nil {
+	if err := writer.Write(csvHeader); err != nil {
 		return fmt.Errorf("写入CSV表头失败: %w", err)
 	}
 
 	// 写入所有数据行
 	for _, result := range w.results {
-		record := []string{
-			result.URL,
-			strconv.Itoa(result.StatusCode),
-			strconv.FormatInt(result.Size, 10),
-			result.Method,
-			strconv.Itoa(result.Depth),
-			result.Timestamp.Format(time.RFC3339),
-			result.Error,
+		if result.Calibration {
+			continue
 		}
 
+		record := csvRecord(result)
+
 		if err := writer.Write(record); err != nil {
 			return fmt.Errorf("写入CSV数据失败: %w", err)
 		}
@@ -346,6 +418,49 @@ func (w *BufferedCSVWriter) Close() error {
 	return w.Flush()
 }
 
+// groupByTarget 将结果按目标分组，同一目标内保持发现顺序
+func groupByTarget(results []*scanner.Result) {
+	order := make(map[string]int)
+	for _, result := range results {
+		if _, ok := order[result.Target]; !ok {
+			order[result.Target] = len(order)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return order[results[i].Target] < order[results[j].Target]
+	})
+}
+
+// IsFilenameTemplate 判断输出文件名是否包含目标占位符
+func IsFilenameTemplate(filename string) bool {
+	return strings.Contains(filename, "{host}") || strings.Contains(filename, "{target}")
+}
+
+// TargetFilename 将文件名模板中的 {host}（主机和端口）和 {target}（完整URL）替换为目标对应的安全文件名
+func TargetFilename(template, target string) string {
+	host := target
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return strings.NewReplacer(
+		"{host}", sanitizeFilename(host),
+		"{target}", sanitizeFilename(strings.Replace(target, "://", "_", 1)),
+	).Replace(template)
+}
+
+// sanitizeFilename 将不适合出现在文件名中的字符替换为下划线
+func sanitizeFilename(name string) string {
+	name = strings.TrimRight(name, "/")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
 // MultiWriter 多重输出器
 type MultiWriter struct {
 	writers []Writer
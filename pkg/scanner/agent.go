@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+
+	"dirsearch-go/pkg/config"
+	"dirsearch-go/pkg/cookies"
+	"dirsearch-go/pkg/useragent"
+)
+
+// identity 发送请求使用的客户端和浏览器特征
+// 按连接轮换用户代理时，每个 identity 有单独的 Transport（每个主机最多一个连接），连接断开重建后仍使用同一个用户代理
+type identity struct {
+	client *http.Client
+	agent  useragent.Profile
+}
+
+// setupAgents 根据内置和自定义的用户代理创建随机列表，按连接轮换时为每个线程准备一个连接
+func (s *Scanner) setupAgents(transport *http.Transport, jar *cookies.Jar) error {
+	profiles := useragent.Builtin()
+	for _, userAgent := range s.config.RandomAgent.List {
+		profiles = append(profiles, useragent.ForAgent(userAgent))
+	}
+	s.agents = useragent.New(profiles, s.config.RandomAgent.Headers)
+
+	if s.config.RandomAgent.Rotation != config.AgentPerConnection {
+		return nil
+	}
+	s.identities = make(chan *identity, s.config.Threads)
+	for range s.config.Threads {
+		t := transport.Clone()
+		t.MaxConnsPerHost = 1
+		t.MaxIdleConnsPerHost = 1
+		client, err := s.newClient(t, jar)
+		if err != nil {
+			return err
+		}
+		s.identities <- &identity{client: client, agent: s.agents.Next()}
+	}
+	return nil
+}
+
+// agent 为请求选择浏览器特征，未启用随机用户代理时使用配置的 UserAgent
+func (s *Scanner) agent() useragent.Profile {
+	if s.agents == nil {
+		return useragent.Profile{UserAgent: s.config.UserAgent}
+	}
+	return s.agents.Next()
+}
+
+// acquire 取出发送请求使用的客户端和浏览器特征，按连接轮换时等待空闲的连接，用完后调用 release 归还
+func (s *Scanner) acquire(ctx context.Context) (*identity, error) {
+	if s.identities == nil {
+		return &identity{client: s.client, agent: s.agent()}, nil
+	}
+	select {
+	case id := <-s.identities:
+		return id, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// release 归还 acquire 取出的连接
+func (s *Scanner) release(id *identity) {
+	if s.identities != nil {
+		s.identities <- id
+	}
+}
+
+// setAgent 设置请求的用户代理，以及与浏览器匹配的 Accept 和 Accept-Language
+func setAgent(req *http.Request, agent useragent.Profile) {
+	req.Header.Set("User-Agent", agent.UserAgent)
+	if agent.Accept != "" {
+		req.Header.Set("Accept", agent.Accept)
+	}
+	if agent.AcceptLanguage != "" {
+		req.Header.Set("Accept-Language", agent.AcceptLanguage)
+	}
+}
@@ -0,0 +1,36 @@
+package scanner
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"dirsearch-go/pkg/wordlist"
+)
+
+// SourceBackup 由命中文件衍生出的备份文件
+const SourceBackup = "backup"
+
+// BackupLinks 为 2xx 的文件命中生成同目录下的备份文件和编辑器临时文件
+// 只处理带扩展名的文件，目录和备份结果本身不会再衍生
+func (s *Scanner) BackupLinks(result *Result) []Link {
+	if result.StatusCode < 200 || result.StatusCode >= 300 || result.Source == SourceBackup {
+		return nil
+	}
+
+	u, err := url.Parse(result.URL)
+	if err != nil || strings.HasSuffix(u.Path, "/") {
+		return nil
+	}
+	dir, file := path.Split(u.Path)
+	if path.Ext(file) == "" {
+		return nil
+	}
+
+	var links []Link
+	for _, variant := range wordlist.BackupVariants(file, s.config.Backups.Patterns) {
+		backup := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: dir + variant}
+		links = append(links, Link{URL: backup.String(), Source: SourceBackup, DerivedFrom: result.URL})
+	}
+	return links
+}
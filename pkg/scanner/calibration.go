@@ -0,0 +1,430 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// reflectPlaceholder 替换响应中回显的请求路径，使不同随机路径的响应可以比较
+const reflectPlaceholder = "§"
+
+// Fingerprint 响应指纹，用于识别软404页面
+type Fingerprint struct {
+	StatusCode int    `json:"status_code"`
+	Size       int64  `json:"size"`
+	Words      int    `json:"words"`
+	Lines      int    `json:"lines"`
+	Location   string `json:"location,omitempty"`
+	BodyHash   string `json:"body_hash"`
+}
+
+// baseline 某一类路径的软404基线
+type baseline struct {
+	method      string
+	kind        string
+	fingerprint Fingerprint
+	stableSize  bool
+	stableHash  bool
+	stableWords bool
+	stableLines bool
+}
+
+// newFingerprint 计算响应指纹
+// 请求中的词条（tokens）会从响应体和重定向地址中剔除，避免页面回显路径导致指纹不一致
+func newFingerprint(statusCode int, tokens []string, body []byte, location string) Fingerprint {
+	for _, token := range tokens {
+		if token == "" {
+			continue
+		}
+		body = bytes.ReplaceAll(body, []byte(token), []byte(reflectPlaceholder))
+		location = strings.ReplaceAll(location, token, reflectPlaceholder)
+		if escaped := url.PathEscape(token); escaped != token {
+			body = bytes.ReplaceAll(body, []byte(escaped), []byte(reflectPlaceholder))
+			location = strings.ReplaceAll(location, escaped, reflectPlaceholder)
+		}
+	}
+
+	sum := sha1.Sum(body)
+	return Fingerprint{
+		StatusCode: statusCode,
+		Size:       int64(len(body)),
+		Words:      countWords(body),
+		Lines:      countLines(body),
+		Location:   location,
+		BodyHash:   hex.EncodeToString(sum[:]),
+	}
+}
+
+// countWords 统计响应体中的单词数
+func countWords(body []byte) int {
+	return len(bytes.Fields(body))
+}
+
+// countLines 统计响应体中的行数
+func countLines(body []byte) int {
+	if len(body) == 0 {
+		return 0
+	}
+	return bytes.Count(body, []byte("\n")) + 1
+}
+
+// lastSegment 返回URL路径的最后一段（已解码）
+func lastSegment(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	p := strings.TrimRight(u.Path, "/")
+	if p == "" {
+		return ""
+	}
+	return path.Base(p)
+}
+
+// pathKind 返回路径的类型：目录为 "/"，带扩展名为 ".ext"，其余为空字符串
+func pathKind(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	if strings.HasSuffix(u.Path, "/") {
+		return "/"
+	}
+	return path.Ext(path.Base(u.Path))
+}
+
+// randomToken 生成用于校准的随机路径片段
+func randomToken() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// calibrationKinds 返回需要校准的路径类型
+func (s *Scanner) calibrationKinds() []string {
+	kinds := []string{"", "/"}
+	for _, ext := range s.config.Scanner.Extensions {
+		ext = strings.TrimPrefix(ext, ".")
+		if ext != "" {
+			kinds = append(kinds, "."+ext)
+		}
+	}
+	return kinds
+}
+
+// calibration 某个路径前缀的校准状态
+type calibration struct {
+	done      chan struct{}
+	baselines map[string]*baseline // 键为 "方法 路径类型"
+}
+
+// baseKey 将URL规范化为目录前缀，作为基线缓存的键
+func baseKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	u.RawPath = ""
+	return u.String()
+}
+
+// parentKey 返回URL所在目录的前缀键，根目录返回空字符串
+func parentKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	p := strings.TrimRight(u.Path, "/")
+	if p == "" {
+		return ""
+	}
+	u.Path = p[:strings.LastIndex(p, "/")+1]
+	u.RawPath = ""
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// Calibrate 请求若干随机的不存在路径，为 baseURL 下的每种方法和路径类型建立软404基线
+// 基线按目录前缀缓存，同一前缀只校准一次；并发调用会等待正在进行的校准完成。
+// 只有实际执行了校准的调用才会返回探测结果，结果已标记 Calibration，可以直接交给输出器记录
+func (s *Scanner) Calibrate(ctx context.Context, baseURL string) ([]*Result, error) {
+	key := baseKey(baseURL)
+
+	s.calibrationMu.Lock()
+	if c, ok := s.calibrations[key]; ok {
+		s.calibrationMu.Unlock()
+		select {
+		case <-c.done:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c := &calibration{
+		done:      make(chan struct{}),
+		baselines: make(map[string]*baseline),
+	}
+	s.calibrations[key] = c
+	s.calibrationMu.Unlock()
+	defer close(c.done)
+
+	var probes []*Result
+	for _, method := range s.config.Scanner.Methods {
+		for _, kind := range s.calibrationKinds() {
+			var results []*Result
+			for i := 0; i < s.config.Calibration.Probes; i++ {
+				if err := ctx.Err(); err != nil {
+					return probes, err
+				}
+
+				token, err := randomToken()
+				if err != nil {
+					return probes, err
+				}
+				probeURL := key + token
+				if kind == "/" {
+					probeURL += "/"
+				} else {
+					probeURL += kind
+				}
+
+				result, err := s.makeRequest(ctx, s.newRequest(method, probeURL), 0)
+				if err != nil || result.Error != "" {
+					continue
+				}
+				result.Calibration = true
+				result.DiscardBody()
+				results = append(results, result)
+			}
+			probes = append(probes, results...)
+
+			b := buildBaseline(method, kind, results)
+			if b == nil {
+				s.logger.Debug("校准响应不稳定，跳过该类路径", "base", key, "method", method, "kind", kind)
+				continue
+			}
+			c.baselines[method+" "+kind] = b
+			s.logger.Info("软404基线", "base", key, "method", method, "kind", kind,
+				"status", b.fingerprint.StatusCode, "size", b.fingerprint.Size,
+				"words", b.fingerprint.Words, "lines", b.fingerprint.Lines,
+				"location", b.fingerprint.Location)
+		}
+	}
+
+	return probes, nil
+}
+
+// fuzzCalibrationKey FUZZ 模式的基线缓存键，模板请求不按目录区分
+const fuzzCalibrationKey = "FUZZ"
+
+// CalibrateFuzz 将随机词条代入请求模板中的所有关键字，为每个模板方法建立基线
+func (s *Scanner) CalibrateFuzz(ctx context.Context) ([]*Result, error) {
+	return s.calibrateMethods(ctx, fuzzCalibrationKey, func(method string) (*request, error) {
+		payload, err := s.randomPayload()
+		if err != nil {
+			return nil, err
+		}
+		return s.fuzzRequest(method, payload), nil
+	})
+}
+
+// calibrateMethods 为每个请求方法建立一组不按目录区分的基线，key 为基线缓存键，probe 创建随机的探测请求
+// 用于 FUZZ 模板和虚拟主机扫描，同一 key 只校准一次
+func (s *Scanner) calibrateMethods(ctx context.Context, key string, probe func(method string) (*request, error)) ([]*Result, error) {
+	s.calibrationMu.Lock()
+	if _, ok := s.calibrations[key]; ok {
+		s.calibrationMu.Unlock()
+		return nil, nil
+	}
+	c := &calibration{
+		done:      make(chan struct{}),
+		baselines: make(map[string]*baseline),
+	}
+	s.calibrations[key] = c
+	s.calibrationMu.Unlock()
+	defer close(c.done)
+
+	var probes []*Result
+	for _, method := range s.config.Scanner.Methods {
+		var results []*Result
+		for i := 0; i < s.config.Calibration.Probes; i++ {
+			if err := ctx.Err(); err != nil {
+				return probes, err
+			}
+
+			r, err := probe(method)
+			if err != nil {
+				return probes, err
+			}
+			result, err := s.makeRequest(ctx, r, 0)
+			if err != nil || result.Error != "" {
+				continue
+			}
+			result.Calibration = true
+			result.DiscardBody()
+			results = append(results, result)
+		}
+		probes = append(probes, results...)
+
+		b := buildBaseline(method, "", results)
+		if b == nil {
+			s.logger.Debug("校准响应不稳定，跳过该请求方法", "base", s.config.Target, "mode", key, "method", method)
+			continue
+		}
+		c.baselines[method+" "] = b
+		s.logger.Info("软404基线", "base", s.config.Target, "mode", key, "method", method,
+			"status", b.fingerprint.StatusCode, "size", b.fingerprint.Size,
+			"words", b.fingerprint.Words, "lines", b.fingerprint.Lines,
+			"location", b.fingerprint.Location)
+	}
+
+	return probes, nil
+}
+
+// buildBaseline 根据同一类路径的校准结果建立基线
+// 状态码和重定向地址必须一致，且至少有一项内容特征稳定，否则无法可靠地区分软404
+func buildBaseline(method, kind string, results []*Result) *baseline {
+	if len(results) == 0 {
+		return nil
+	}
+
+	first := results[0].fingerprint
+	b := &baseline{
+		method:      method,
+		kind:        kind,
+		fingerprint: first,
+		stableSize:  true,
+		stableHash:  true,
+		stableWords: true,
+		stableLines: true,
+	}
+
+	for _, result := range results[1:] {
+		fp := result.fingerprint
+		if fp.StatusCode != first.StatusCode || fp.Location != first.Location {
+			return nil
+		}
+		b.stableSize = b.stableSize && fp.Size == first.Size
+		b.stableHash = b.stableHash && fp.BodyHash == first.BodyHash
+		b.stableWords = b.stableWords && fp.Words == first.Words
+		b.stableLines = b.stableLines && fp.Lines == first.Lines
+	}
+
+	if b.redirect() || b.stableSize || b.stableHash || (b.stableWords && b.stableLines) {
+		return b
+	}
+	return nil
+}
+
+// redirect 判断基线是否为固定地址的重定向
+func (b *baseline) redirect() bool {
+	return b.fingerprint.StatusCode >= 300 && b.fingerprint.StatusCode < 400 && b.fingerprint.Location != ""
+}
+
+// matches 判断指纹是否与基线一致
+func (b *baseline) matches(fp Fingerprint) bool {
+	if fp.StatusCode != b.fingerprint.StatusCode || fp.Location != b.fingerprint.Location {
+		return false
+	}
+
+	switch {
+	case b.redirect():
+		return true
+	case b.stableHash && fp.BodyHash == b.fingerprint.BodyHash:
+		return true
+	case b.stableSize && fp.Size == b.fingerprint.Size:
+		return true
+	case b.stableWords && b.stableLines && fp.Words == b.fingerprint.Words && fp.Lines == b.fingerprint.Lines:
+		// 单词数和行数一致时，大小仍需接近，避免把内容很短的真实页面误判为软404
+		diff := fp.Size - b.fingerprint.Size
+		if diff < 0 {
+			diff = -diff
+		}
+		return diff*10 <= b.fingerprint.Size
+	}
+	return false
+}
+
+// lookupBaseline 从结果所在目录开始逐级向上查找已完成校准的前缀
+func (s *Scanner) lookupBaseline(result *Result) *baseline {
+	s.calibrationMu.Lock()
+	defer s.calibrationMu.Unlock()
+
+	if len(s.calibrations) == 0 {
+		return nil
+	}
+
+	// FUZZ 模板请求和虚拟主机请求只有一组基线
+	if result.fuzz {
+		return s.methodBaseline(fuzzCalibrationKey, result.template)
+	}
+	if result.Host != "" {
+		return s.methodBaseline(vhostCalibrationKey, result.Method)
+	}
+
+	kind := pathKind(result.URL)
+	for key := parentKey(result.URL); key != ""; key = parentKey(key) {
+		c, ok := s.calibrations[key]
+		if !ok {
+			continue
+		}
+		select {
+		case <-c.done:
+		default:
+			// 该前缀仍在校准中，使用上一级的基线
+			continue
+		}
+
+		if b, ok := c.baselines[result.Method+" "+kind]; ok {
+			return b
+		}
+		if b, ok := c.baselines[result.Method+" "]; ok {
+			return b
+		}
+		// 该前缀的响应不稳定，没有可用的基线，继续使用上一级的基线
+	}
+	return nil
+}
+
+// methodBaseline 返回 calibrateMethods 建立的基线，校准尚未完成时返回 nil，调用方需持有 calibrationMu
+func (s *Scanner) methodBaseline(key, method string) *baseline {
+	c, ok := s.calibrations[key]
+	if !ok {
+		return nil
+	}
+	select {
+	case <-c.done:
+		return c.baselines[method+" "]
+	default:
+		return nil
+	}
+}
+
+// matchesBaseline 判断结果是否与所在目录的软404基线一致
+func (s *Scanner) matchesBaseline(result *Result) bool {
+	b := s.lookupBaseline(result)
+	if b == nil || !b.matches(result.fingerprint) {
+		return false
+	}
+
+	if result.Host != "" {
+		s.logger.Debug("响应与虚拟主机基线一致，已忽略", "url", result.URL, "host", result.Host, "method", result.Method)
+		return true
+	}
+	s.logger.Debug("响应与软404基线一致，已忽略", "url", result.URL, "method", result.Method, "kind", b.kind)
+	return true
+}
@@ -0,0 +1,170 @@
+package scanner
+
+import "testing"
+
+// probes 将指纹包装为校准结果
+func probes(fps ...Fingerprint) []*Result {
+	results := make([]*Result, 0, len(fps))
+	for _, fp := range fps {
+		results = append(results, &Result{fingerprint: fp})
+	}
+	return results
+}
+
+func TestBuildBaseline(t *testing.T) {
+	static := Fingerprint{StatusCode: 200, Size: 100, Words: 10, Lines: 2, BodyHash: "a"}
+
+	tests := []struct {
+		name    string
+		results []*Result
+		want    bool
+	}{
+		{"无探测结果", nil, false},
+		{"完全一致", probes(static, static, static), true},
+		{"状态码不一致", probes(static, Fingerprint{StatusCode: 404, Size: 100, Words: 10, Lines: 2, BodyHash: "a"}), false},
+		{"重定向地址不一致", probes(
+			Fingerprint{StatusCode: 302, Location: "/a"},
+			Fingerprint{StatusCode: 302, Location: "/b"},
+		), false},
+		{"大小稳定", probes(static, Fingerprint{StatusCode: 200, Size: 100, Words: 11, Lines: 3, BodyHash: "b"}), true},
+		{"单词数和行数稳定", probes(static, Fingerprint{StatusCode: 200, Size: 104, Words: 10, Lines: 2, BodyHash: "b"}), true},
+		{"只有单词数稳定", probes(static, Fingerprint{StatusCode: 200, Size: 104, Words: 10, Lines: 3, BodyHash: "b"}), false},
+		{"固定地址的重定向", probes(
+			Fingerprint{StatusCode: 302, Size: 10, Location: "/login", BodyHash: "a"},
+			Fingerprint{StatusCode: 302, Size: 20, Words: 3, Location: "/login", BodyHash: "b"},
+		), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildBaseline("GET", "", tt.results) != nil; got != tt.want {
+				t.Errorf("buildBaseline() != nil = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaselineMatches(t *testing.T) {
+	base := Fingerprint{StatusCode: 200, Size: 100, Words: 10, Lines: 2, BodyHash: "a"}
+
+	tests := []struct {
+		name     string
+		baseline baseline
+		fp       Fingerprint
+		want     bool
+	}{
+		{
+			name:     "哈希一致",
+			baseline: baseline{fingerprint: base, stableHash: true},
+			fp:       Fingerprint{StatusCode: 200, Size: 100, Words: 10, Lines: 2, BodyHash: "a"},
+			want:     true,
+		},
+		{
+			name:     "状态码不同",
+			baseline: baseline{fingerprint: base, stableHash: true, stableSize: true},
+			fp:       Fingerprint{StatusCode: 403, Size: 100, Words: 10, Lines: 2, BodyHash: "a"},
+			want:     false,
+		},
+		{
+			name:     "哈希不稳定时按大小比较",
+			baseline: baseline{fingerprint: base, stableSize: true},
+			fp:       Fingerprint{StatusCode: 200, Size: 100, BodyHash: "b"},
+			want:     true,
+		},
+		{
+			name:     "大小不同",
+			baseline: baseline{fingerprint: base, stableSize: true, stableHash: true},
+			fp:       Fingerprint{StatusCode: 200, Size: 101, Words: 10, Lines: 2, BodyHash: "b"},
+			want:     false,
+		},
+		{
+			name:     "单词数和行数一致且大小接近",
+			baseline: baseline{fingerprint: base, stableWords: true, stableLines: true},
+			fp:       Fingerprint{StatusCode: 200, Size: 108, Words: 10, Lines: 2, BodyHash: "b"},
+			want:     true,
+		},
+		{
+			name:     "单词数和行数一致但大小相差超过10%",
+			baseline: baseline{fingerprint: base, stableWords: true, stableLines: true},
+			fp:       Fingerprint{StatusCode: 200, Size: 150, Words: 10, Lines: 2, BodyHash: "b"},
+			want:     false,
+		},
+		{
+			name:     "行数不同",
+			baseline: baseline{fingerprint: base, stableWords: true, stableLines: true},
+			fp:       Fingerprint{StatusCode: 200, Size: 100, Words: 10, Lines: 3, BodyHash: "b"},
+			want:     false,
+		},
+		{
+			name:     "固定地址的重定向",
+			baseline: baseline{fingerprint: Fingerprint{StatusCode: 302, Location: "/login"}},
+			fp:       Fingerprint{StatusCode: 302, Size: 500, Location: "/login", BodyHash: "c"},
+			want:     true,
+		},
+		{
+			name:     "重定向到其他地址",
+			baseline: baseline{fingerprint: Fingerprint{StatusCode: 302, Location: "/login"}},
+			fp:       Fingerprint{StatusCode: 302, Location: "/admin/"},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.baseline.matches(tt.fp); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFingerprintStripsReflection(t *testing.T) {
+	a := newFingerprint(200, []string{"abc123"}, []byte("<p>/abc123 not found</p>"), "/abc123/")
+	b := newFingerprint(200, []string{"zzz999"}, []byte("<p>/zzz999 not found</p>"), "/zzz999/")
+	if a != b {
+		t.Errorf("回显不同路径的响应指纹不一致: %+v != %+v", a, b)
+	}
+}
+
+func TestLookupBaseline(t *testing.T) {
+	root := &baseline{fingerprint: Fingerprint{StatusCode: 404}}
+	admin := &baseline{fingerprint: Fingerprint{StatusCode: 403}}
+	done := make(chan struct{})
+	close(done)
+
+	s := &Scanner{calibrations: map[string]*calibration{
+		"http://example.com/": {done: done, baselines: map[string]*baseline{
+			"GET ":  root,
+			"GET /": root,
+		}},
+		// 只有扩展名页面的响应稳定
+		"http://example.com/admin/": {done: done, baselines: map[string]*baseline{
+			"GET .php": admin,
+		}},
+		// 响应不稳定的目录没有建立基线
+		"http://example.com/unstable/": {done: done, baselines: map[string]*baseline{}},
+		// 仍在校准中的目录
+		"http://example.com/pending/": {done: make(chan struct{}), baselines: map[string]*baseline{}},
+	}}
+
+	tests := []struct {
+		name string
+		url  string
+		want *baseline
+	}{
+		{"所在目录的基线", "http://example.com/admin/login.php", admin},
+		{"所在目录没有该路径类型的基线", "http://example.com/admin/login", root},
+		{"子目录使用上级目录的基线", "http://example.com/admin/static/app.php", admin},
+		{"所在目录没有基线时使用上级目录的基线", "http://example.com/unstable/index", root},
+		{"所在目录仍在校准中", "http://example.com/pending/index", root},
+		{"其他主机", "http://other.example.com/index", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.lookupBaseline(&Result{URL: tt.url, Method: "GET"}); got != tt.want {
+				t.Errorf("lookupBaseline(%s) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
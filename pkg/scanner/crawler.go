@@ -0,0 +1,165 @@
+package scanner
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// 结果来源
+const (
+	SourceWordlist = "wordlist" // 词典条目
+	SourceCrawl    = "crawl"    // 从HTML/CSS中提取的链接
+	SourceJS       = "js"       // 从JavaScript中提取的路径和接口
+)
+
+var (
+	// attrRegex 匹配HTML中引用资源的属性（a/link 的 href、script/img 的 src、form 的 action 等）
+	attrRegex = regexp.MustCompile(`(?i)\b(?:href|src|action|formaction|data-src|data-url|poster)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	// baseHrefRegex 匹配 <base href="..."> 标签
+	baseHrefRegex = regexp.MustCompile(`(?i)<base\s[^>]*href\s*=\s*["']([^"']+)["']`)
+	// metaRefreshRegex 匹配 <meta http-equiv="refresh" content="0;url=...">
+	metaRefreshRegex = regexp.MustCompile(`(?i)<meta\s[^>]*http-equiv\s*=\s*["']?refresh["']?[^>]*content\s*=\s*["'][^"']*?url\s*=\s*['"]?([^"'>\s;]+)`)
+	// cssURLRegex 匹配CSS中的 url(...) 和 @import
+	cssURLRegex = regexp.MustCompile(`(?i)(?:url\(\s*["']?([^"')\s]+)["']?\s*\)|@import\s+["']([^"']+)["'])`)
+	// scriptRegex 匹配内联脚本
+	scriptRegex = regexp.MustCompile(`(?is)<script\b[^>]*>(.*?)</script>`)
+	// styleRegex 匹配内联样式
+	styleRegex = regexp.MustCompile(`(?is)<style\b[^>]*>(.*?)</style>`)
+	// jsCallRegex 匹配 fetch("/api")、axios.get("/api")、xhr.open("GET", "/api")、$.ajax({url: "/api"}) 等调用
+	jsCallRegex = regexp.MustCompile(`(?i)(?:fetch|axios(?:\.(?:get|post|put|patch|delete|head|request))?|\$\.(?:get|post|getJSON|ajax)|\.open\(\s*["'][A-Z]+["']\s*,|\burl\s*:)\s*\(?\s*["'\x60]([^"'\x60\s]+)["'\x60]`)
+	// jsPathRegex 匹配JavaScript字符串字面量中看起来像路径的内容
+	jsPathRegex = regexp.MustCompile(`["'\x60]((?:/|\.\./|\./)[a-zA-Z0-9_\-./%~?=&]+|[a-zA-Z0-9_\-]+/[a-zA-Z0-9_\-./%~]+\.[a-zA-Z0-9]{1,6}(?:\?[^"'\x60\s]*)?|[a-zA-Z0-9_\-]+\.(?:php|asp|aspx|jsp|jspx|do|action|json|xml|js|html?|txt|cgi)(?:\?[^"'\x60\s]*)?)["'\x60]`)
+)
+
+// staticExtensions 不需要请求的静态资源扩展名
+var staticExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".bmp": true, ".ico": true,
+	".svg": true, ".webp": true, ".woff": true, ".woff2": true, ".ttf": true, ".eot": true,
+	".otf": true, ".mp3": true, ".mp4": true, ".webm": true, ".avi": true, ".mov": true,
+}
+
+// Link 爬虫发现的链接
+type Link struct {
+	URL         string // 绝对地址
+	Source      string // 发现方式：crawl、js 等
+	DerivedFrom string // 衍生出该链接的命中结果，例如备份文件对应的原文件
+}
+
+// ExtractLinks 从命中的响应中提取链接和接口（用于递归扫描）
+// HTML 会解析各类资源属性、<meta refresh>、内联脚本和样式；JavaScript 和 CSS 文件按各自的语法提取。
+// 链接相对于页面自身的URL解析为绝对地址，只保留与目标同源且在递归范围内的链接
+func (s *Scanner) ExtractLinks(result *Result) []Link {
+	if len(result.body) == 0 {
+		return nil
+	}
+
+	page, err := url.Parse(result.URL)
+	if err != nil {
+		return nil
+	}
+
+	c := &crawl{scanner: s, seen: make(map[string]bool)}
+	body := string(result.body)
+
+	switch contentKind(page, result.contentType) {
+	case "js":
+		// 脚本中的相对路径通常相对于引用它的页面，无法得知时以目标地址为基准
+		c.extractJS(s.target, body)
+	case "css":
+		c.extractCSS(page, body)
+	default:
+		c.extractHTML(page, body)
+	}
+
+	return c.links
+}
+
+// crawl 单个响应的链接提取状态
+type crawl struct {
+	scanner *Scanner
+	seen    map[string]bool
+	links   []Link
+}
+
+// add 解析并记录一个链接
+func (c *crawl) add(base *url.URL, raw, source string) {
+	raw = strings.TrimSpace(raw)
+	// 跳过模板占位符
+	if strings.Contains(raw, "${") || strings.Contains(raw, "{{") {
+		return
+	}
+
+	link, ok := c.scanner.resolveLink(base, raw)
+	if !ok || c.seen[link] {
+		return
+	}
+	if u, err := url.Parse(link); err == nil && staticExtensions[strings.ToLower(path.Ext(u.Path))] {
+		return
+	}
+
+	c.seen[link] = true
+	c.links = append(c.links, Link{URL: link, Source: source})
+}
+
+// extractHTML 提取HTML中的链接
+func (c *crawl) extractHTML(page *url.URL, body string) {
+	// 页面声明了 <base href> 时，相对链接以它为基准
+	if match := baseHrefRegex.FindStringSubmatch(body); match != nil {
+		if base, err := page.Parse(strings.TrimSpace(match[1])); err == nil {
+			page = base
+		}
+	}
+
+	for _, match := range attrRegex.FindAllStringSubmatch(body, -1) {
+		c.add(page, match[1]+match[2], SourceCrawl)
+	}
+	for _, match := range metaRefreshRegex.FindAllStringSubmatch(body, -1) {
+		c.add(page, match[1], SourceCrawl)
+	}
+	for _, match := range styleRegex.FindAllStringSubmatch(body, -1) {
+		c.extractCSS(page, match[1])
+	}
+	for _, match := range scriptRegex.FindAllStringSubmatch(body, -1) {
+		c.extractJS(page, match[1])
+	}
+}
+
+// extractCSS 提取CSS中的 url() 和 @import
+func (c *crawl) extractCSS(base *url.URL, body string) {
+	for _, match := range cssURLRegex.FindAllStringSubmatch(body, -1) {
+		c.add(base, match[1]+match[2], SourceCrawl)
+	}
+}
+
+// extractJS 提取JavaScript中的接口调用和路径字符串
+func (c *crawl) extractJS(base *url.URL, body string) {
+	for _, match := range jsCallRegex.FindAllStringSubmatch(body, -1) {
+		c.add(base, match[1], SourceJS)
+	}
+	for _, match := range jsPathRegex.FindAllStringSubmatch(body, -1) {
+		c.add(base, match[1], SourceJS)
+	}
+}
+
+// contentKind 根据 Content-Type 和扩展名判断响应类型：html、js 或 css
+func contentKind(u *url.URL, contentType string) string {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "javascript") || strings.Contains(contentType, "ecmascript"):
+		return "js"
+	case strings.Contains(contentType, "text/css"):
+		return "css"
+	case strings.Contains(contentType, "html"):
+		return "html"
+	}
+
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".js", ".mjs":
+		return "js"
+	case ".css":
+		return "css"
+	}
+	return "html"
+}
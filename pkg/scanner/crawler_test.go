@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractLinks(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		want        []Link
+	}{
+		{
+			name:        "HTML",
+			url:         "https://example.com/app/index.html",
+			contentType: "text/html; charset=utf-8",
+			body: `<a href="login.php">登录</a>
+<img src='/img/logo.png'>
+<form action="/submit" method="post"></form>
+<a href="mailto:admin@example.com">联系</a>
+<a href="#top">顶部</a>
+<a href="login.php">重复</a>
+<a href="https://other.example.com/">外部</a>
+<a href="{{ url }}">模板</a>
+<meta http-equiv="refresh" content="0; url=/home">
+<style>body { background: url(/bg/) }</style>
+<script>fetch("/api/users")</script>`,
+			want: []Link{
+				{URL: "https://example.com/app/login.php", Source: SourceCrawl},
+				{URL: "https://example.com/submit", Source: SourceCrawl},
+				{URL: "https://example.com/home", Source: SourceCrawl},
+				{URL: "https://example.com/bg/", Source: SourceCrawl},
+				{URL: "https://example.com/api/users", Source: SourceJS},
+			},
+		},
+		{
+			name: "base href",
+			url:  "https://example.com/app/index.html",
+			body: `<base href="/static/"><a href="app.php">`,
+			want: []Link{
+				{URL: "https://example.com/static/", Source: SourceCrawl},
+				{URL: "https://example.com/static/app.php", Source: SourceCrawl},
+			},
+		},
+		{
+			name:        "CSS",
+			url:         "https://example.com/css/site.css",
+			contentType: "text/css",
+			body: `@import "theme.css";
+.a { background: url(img/bg.png) }
+.b { src: url("../fonts/") }
+.c { background: url( 'sprites/' ) }
+.d { background: url(data:image/png;base64,AAAA) }`,
+			want: []Link{
+				{URL: "https://example.com/css/theme.css", Source: SourceCrawl},
+				{URL: "https://example.com/fonts/", Source: SourceCrawl},
+				{URL: "https://example.com/css/sprites/", Source: SourceCrawl},
+			},
+		},
+		{
+			name:        "JavaScript",
+			url:         "https://example.com/static/js/app.js",
+			contentType: "application/javascript",
+			body: "axios.get(\"/api/v1/users\");\n" +
+				"xhr.open(\"POST\", \"/api/login\");\n" +
+				"const config = \"config.json\";\n" +
+				"var admin = '../admin/';\n" +
+				"let tpl = `${base}/x`;\n" +
+				"var msg = \"hello world\";\n",
+			want: []Link{
+				{URL: "https://example.com/api/v1/users", Source: SourceJS},
+				{URL: "https://example.com/api/login", Source: SourceJS},
+				{URL: "https://example.com/config.json", Source: SourceJS},
+				{URL: "https://example.com/admin/", Source: SourceJS},
+			},
+		},
+		{
+			name: "空响应体",
+			url:  "https://example.com/",
+		},
+	}
+
+	s := linkScanner("https://example.com/", nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Result{URL: tt.url, body: []byte(tt.body), contentType: tt.contentType}
+			if got := s.ExtractLinks(result); !slices.Equal(got, tt.want) {
+				t.Errorf("ExtractLinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentKind(t *testing.T) {
+	tests := []struct {
+		url         string
+		contentType string
+		want        string
+	}{
+		{"https://example.com/", "text/html", "html"},
+		{"https://example.com/app.js", "application/javascript", "js"},
+		{"https://example.com/app", "text/ecmascript", "js"},
+		{"https://example.com/site.css", "text/css; charset=utf-8", "css"},
+		{"https://example.com/app.js", "", "js"},
+		{"https://example.com/app.mjs", "", "js"},
+		{"https://example.com/SITE.CSS", "", "css"},
+		{"https://example.com/app.js", "text/html", "html"},
+		{"https://example.com/data", "application/octet-stream", "html"},
+	}
+
+	for _, tt := range tests {
+		if got := contentKind(mustParseURL(tt.url), tt.contentType); got != tt.want {
+			t.Errorf("contentKind(%q, %q) = %q, want %q", tt.url, tt.contentType, got, tt.want)
+		}
+	}
+}
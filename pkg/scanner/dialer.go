@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"dirsearch-go/pkg/config"
+)
+
+// dialFunc 建立连接的函数，与 http.Transport.DialContext 一致
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// newDialer 根据 -resolve 和自定义 DNS 服务器创建连接函数，都未配置时返回 nil（使用 Transport 的默认行为）
+// 只改变连接的地址，URL、Host 请求头和 SNI 仍然使用原来的主机名
+func newDialer(cfg *config.Config) (dialFunc, error) {
+	if len(cfg.Scanner.Resolve) == 0 && cfg.Scanner.DNSServer == "" {
+		return nil, nil
+	}
+
+	overrides, err := parseResolve(cfg.Scanner.Resolve)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: time.Duration(cfg.Timeout), KeepAlive: 30 * time.Second}
+	if cfg.Scanner.DNSServer != "" {
+		server := cfg.Scanner.DNSServer
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+		// 使用 Go 自带的解析器，查询发往指定的服务器
+		dialer.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, server)
+			},
+		}
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			host = strings.ToLower(host)
+			if ip, ok := overrides[host+":"+port]; ok {
+				addr = net.JoinHostPort(ip, port)
+			} else if ip, ok := overrides[host+":*"]; ok {
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}, nil
+}
+
+// parseResolve 解析 host:port:ip 格式的地址映射，端口可以写成 * 匹配所有端口，IPv6 地址可以加方括号
+func parseResolve(entries []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("无效的地址映射: %s (格式为 host:port:ip)", entry)
+		}
+		if port, err := strconv.Atoi(parts[1]); parts[1] != "*" && (err != nil || port <= 0 || port > 65535) {
+			return nil, fmt.Errorf("无效的地址映射: %s (端口 %s 无效)", entry, parts[1])
+		}
+		ip := strings.TrimSuffix(strings.TrimPrefix(parts[2], "["), "]")
+		if net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("无效的地址映射: %s (%s 不是IP地址)", entry, parts[2])
+		}
+		overrides[strings.ToLower(parts[0])+":"+parts[1]] = ip
+	}
+	return overrides, nil
+}
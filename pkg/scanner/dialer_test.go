@@ -0,0 +1,45 @@
+package scanner
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParseResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"没有映射", nil, map[string]string{}, false},
+		{"IPv4", []string{"example.com:443:10.0.0.12"}, map[string]string{"example.com:443": "10.0.0.12"}, false},
+		{"主机名转为小写", []string{"API.Example.com:80:10.0.0.1"}, map[string]string{"api.example.com:80": "10.0.0.1"}, false},
+		{"匹配所有端口", []string{"example.com:*:10.0.0.12"}, map[string]string{"example.com:*": "10.0.0.12"}, false},
+		{"IPv6", []string{"example.com:443:::1"}, map[string]string{"example.com:443": "::1"}, false},
+		{"带方括号的IPv6", []string{"example.com:443:[2001:db8::1]"}, map[string]string{"example.com:443": "2001:db8::1"}, false},
+		{
+			"多个映射",
+			[]string{"a.example.com:*:10.0.0.1", "b.example.com:8443:10.0.0.2"},
+			map[string]string{"a.example.com:*": "10.0.0.1", "b.example.com:8443": "10.0.0.2"},
+			false,
+		},
+		{"缺少IP", []string{"example.com:443"}, nil, true},
+		{"缺少主机", []string{":443:10.0.0.1"}, nil, true},
+		{"端口不是数字", []string{"example.com:https:10.0.0.1"}, nil, true},
+		{"端口超出范围", []string{"example.com:65536:10.0.0.1"}, nil, true},
+		{"不是IP地址", []string{"example.com:443:origin.example.com"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseResolve(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseResolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !maps.Equal(got, tt.want) {
+				t.Errorf("parseResolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
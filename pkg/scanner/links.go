@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// compilePatterns 编译正则表达式列表
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// resolveLink 将页面中的链接解析为绝对地址，并判断是否允许跟随
+func (s *Scanner) resolveLink(page *url.URL, raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.HasPrefix(raw, "#") {
+		return "", false
+	}
+
+	// 过滤掉特殊协议
+	lower := strings.ToLower(raw)
+	for _, prefix := range []string{"mailto:", "javascript:", "data:", "tel:"} {
+		if strings.HasPrefix(lower, prefix) {
+			return "", false
+		}
+	}
+
+	u, err := page.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	u.Fragment = ""
+
+	if !sameOrigin(u, s.target) {
+		return "", false
+	}
+
+	link := u.String()
+	if !s.InScope(link) {
+		return "", false
+	}
+	return link, true
+}
+
+// InScope 判断URL是否在配置的递归范围内
+// 配置了包含规则时必须至少匹配一条，且不能匹配任何排除规则
+func (s *Scanner) InScope(rawURL string) bool {
+	if len(s.scopeInclude) > 0 {
+		included := false
+		for _, re := range s.scopeInclude {
+			if re.MatchString(rawURL) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, re := range s.scopeExclude {
+		if re.MatchString(rawURL) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameOrigin 判断两个URL的协议、主机和端口是否一致
+func sameOrigin(a, b *url.URL) bool {
+	if !strings.EqualFold(a.Scheme, b.Scheme) {
+		return false
+	}
+	return strings.EqualFold(hostWithPort(a), hostWithPort(b))
+}
+
+// hostWithPort 返回带端口的主机名，缺省端口按协议补全
+func hostWithPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "https":
+			port = "443"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// ParentDirectory 返回URL所在目录（以 / 结尾），根目录返回空字符串
+func ParentDirectory(rawURL string) string {
+	return parentKey(rawURL)
+}
@@ -0,0 +1,136 @@
+package scanner
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+// linkScanner 创建只用于解析链接的扫描器
+func linkScanner(target string, include, exclude []string) *Scanner {
+	s := &Scanner{target: mustParseURL(target)}
+	for _, pattern := range include {
+		s.scopeInclude = append(s.scopeInclude, regexp.MustCompile(pattern))
+	}
+	for _, pattern := range exclude {
+		s.scopeExclude = append(s.scopeExclude, regexp.MustCompile(pattern))
+	}
+	return s
+}
+
+func mustParseURL(rawURL string) *url.URL {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func TestResolveLink(t *testing.T) {
+	s := linkScanner("https://example.com/", nil, []string{`/logout`})
+	page := mustParseURL("https://example.com/app/admin/index.php")
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+		ok   bool
+	}{
+		{"相对路径", "users.php", "https://example.com/app/admin/users.php", true},
+		{"绝对路径", "/api/v1/", "https://example.com/api/v1/", true},
+		{"上级目录", "../static/app.js", "https://example.com/app/static/app.js", true},
+		{"超出根目录的上级目录", "../../../config.php", "https://example.com/config.php", true},
+		{"完整地址", "https://example.com/login", "https://example.com/login", true},
+		{"协议相对地址", "//example.com/cdn/lib.js", "https://example.com/cdn/lib.js", true},
+		{"其他主机的协议相对地址", "//cdn.example.net/lib.js", "", false},
+		{"其他协议", "http://example.com/login", "", false},
+		{"去掉片段", "users.php#top", "https://example.com/app/admin/users.php", true},
+		{"保留查询参数", "search?q=1", "https://example.com/app/admin/search?q=1", true},
+		{"首尾空白", "  users.php\n", "https://example.com/app/admin/users.php", true},
+		{"只有片段", "#top", "", false},
+		{"空链接", " ", "", false},
+		{"javascript 伪协议", "javascript:void(0)", "", false},
+		{"大写的伪协议", "JavaScript:alert(1)", "", false},
+		{"mailto", "mailto:admin@example.com", "", false},
+		{"tel", "tel:+10000000000", "", false},
+		{"data", "data:text/html,hi", "", false},
+		{"排除范围", "/logout", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := s.resolveLink(page, tt.raw)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("resolveLink(%q) = %q, %v, want %q, %v", tt.raw, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestInScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		url     string
+		want    bool
+	}{
+		{"未配置规则", nil, nil, "https://example.com/any", true},
+		{"匹配包含规则", []string{`/api/`, `/admin/`}, nil, "https://example.com/admin/users", true},
+		{"不匹配任何包含规则", []string{`/api/`}, nil, "https://example.com/static/app.js", false},
+		{"匹配排除规则", nil, []string{`\.pdf$`}, "https://example.com/doc/manual.pdf", false},
+		{"排除规则优先", []string{`/api/`}, []string{`/api/v1/logout`}, "https://example.com/api/v1/logout", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := linkScanner("https://example.com/", tt.include, tt.exclude)
+			if got := s.InScope(tt.url); got != tt.want {
+				t.Errorf("InScope(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://example.com/a", "https://example.com/b", true},
+		{"http://example.com/", "http://example.com:80/", true},
+		{"https://example.com/", "https://example.com:443/", true},
+		{"HTTPS://Example.COM/", "https://example.com/", true},
+		{"https://example.com/", "http://example.com/", false},
+		{"https://example.com/", "https://example.com:8443/", false},
+		{"http://example.com:443/", "https://example.com/", false},
+		{"https://example.com/", "https://www.example.com/", false},
+		{"http://[::1]/", "http://[::1]:80/", true},
+	}
+
+	for _, tt := range tests {
+		if got := sameOrigin(mustParseURL(tt.a), mustParseURL(tt.b)); got != tt.want {
+			t.Errorf("sameOrigin(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParentDirectory(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.com/admin/users.php", "https://example.com/admin/"},
+		{"https://example.com/admin/", "https://example.com/"},
+		{"https://example.com/admin", "https://example.com/"},
+		{"https://example.com/a/b/c/?q=1#top", "https://example.com/a/b/"},
+		{"https://example.com/", ""},
+		{"https://example.com", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ParentDirectory(tt.url); got != tt.want {
+			t.Errorf("ParentDirectory(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
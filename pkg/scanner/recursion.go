@@ -0,0 +1,85 @@
+package scanner
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"dirsearch-go/pkg/config"
+)
+
+// IsDirectory 判断结果是否指向一个可以继续爆破的目录
+// 满足以下任一条件即视为目录：路径以 / 结尾；301/302/307/308 重定向到 路径/；403 且路径最后一段没有扩展名
+func (s *Scanner) IsDirectory(result *Result) bool {
+	if result == nil || result.Error != "" || result.Calibration {
+		return false
+	}
+
+	allowed := false
+	for _, code := range s.config.Recursion.StatusCodes {
+		if result.StatusCode == code {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return false
+	}
+
+	u, err := url.Parse(result.URL)
+	if err != nil {
+		return false
+	}
+	if s.isExcludedSubdir(u.Path) {
+		return false
+	}
+
+	switch {
+	case strings.HasSuffix(u.Path, "/"):
+		return true
+	case result.StatusCode == 301 || result.StatusCode == 302 || result.StatusCode == 307 || result.StatusCode == 308:
+		location, err := u.Parse(result.Location)
+		return err == nil && location.Host == u.Host && location.Path == u.Path+"/"
+	case result.StatusCode == 403:
+		return path.Ext(u.Path) == ""
+	}
+	return false
+}
+
+// dirRecursion 判断是否启用了目录爆破递归
+func (s *Scanner) dirRecursion() bool {
+	return s.config.Recursive && s.config.Recursion.Mode != config.RecursionLinks
+}
+
+// isExcludedSubdir 判断目录是否在排除列表中，既可以匹配目录名也可以匹配相对目标的路径
+func (s *Scanner) isExcludedSubdir(dirPath string) bool {
+	trimmed := strings.Trim(dirPath, "/")
+	name := path.Base(trimmed)
+	for _, excluded := range s.config.Recursion.ExcludeSubdirs {
+		excluded = strings.Trim(excluded, "/")
+		if excluded == name || excluded == trimmed {
+			return true
+		}
+		if base, err := url.Parse(s.config.Target); err == nil {
+			if strings.Trim(strings.TrimPrefix(dirPath, base.Path), "/") == excluded {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DirectoryURL 返回目录结果对应的目录URL（以 / 结尾）
+func DirectoryURL(result *Result) string {
+	u, err := url.Parse(result.URL)
+	if err != nil {
+		return result.URL
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+		u.RawPath = ""
+	}
+	return u.String()
+}
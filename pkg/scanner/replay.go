@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"time"
+
+	"dirsearch-go/pkg/auth"
+	"dirsearch-go/pkg/config"
+	"dirsearch-go/pkg/cookies"
+	"dirsearch-go/pkg/proxy"
+	"dirsearch-go/pkg/useragent"
+)
+
+// newReplayClient 创建通过重放代理发送请求的HTTP客户端
+// 拦截代理会用自己的证书解密 HTTPS，因此不校验证书；重定向原样交给代理记录，不跟随
+// 与扫描共用 Cookie 罐，使重放的请求携带相同的会话
+func newReplayClient(cfg *config.Config, jar *cookies.Jar) (*http.Client, error) {
+	proxyURL, err := proxy.Parse(cfg.Proxy.Replay)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		IdleConnTimeout: 30 * time.Second,
+	}
+	client := &http.Client{
+		Timeout:   time.Duration(cfg.Timeout),
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	if jar != nil {
+		client.Jar = jar
+	}
+
+	// 重放的请求同样需要认证
+	if cfg.Auth.Type != "" {
+		if client.Transport, err = auth.New(credentials(cfg), transport); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// replay 通过重放代理再次发送命中的请求，使代理工具的历史记录中只有真实的发现
+// 重放的请求使用与原请求相同的浏览器特征；重放失败只记录日志，不影响扫描结果
+func (s *Scanner) replay(ctx context.Context, r *request, agent useragent.Profile) {
+	req, err := s.prepare(ctx, r, agent)
+	if err != nil {
+		s.logger.Warn("重放请求失败", "url", r.url, "error", err)
+		return
+	}
+
+	resp, err := s.do(s.replayClient, req)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("重放请求失败", "url", r.url, "error", err)
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	// 读完响应体，代理才能记录完整的响应
+	io.Copy(io.Discard, resp.Body)
+	s.logger.Debug("已重放请求", "url", r.url, "method", r.method, "status", resp.StatusCode)
+}
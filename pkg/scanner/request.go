@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// request 待发送的HTTP请求
+type request struct {
+	method   string
+	url      string
+	headers  map[string]string
+	body     string
+	payload  map[string]string // 代入模板的各关键字词条，计算指纹时会从响应中剔除
+	template string            // 模板中的请求方法，为空表示不是模板请求
+	host     string            // 虚拟主机扫描时的 Host 请求头，为空表示使用URL中的主机
+}
+
+// newRequest 创建对指定URL的普通请求
+func (s *Scanner) newRequest(method, rawURL string) *request {
+	return &request{
+		method:  method,
+		url:     rawURL,
+		headers: s.config.Headers,
+		body:    s.config.RequestBody(nil),
+	}
+}
+
+// fuzzRequest 将词条代入请求模板：URL（包括主机和查询参数）、请求头、请求体和请求方法中的关键字都会被替换
+func (s *Scanner) fuzzRequest(method string, payload map[string]string) *request {
+	// 较长的关键字优先替换，避免 FUZZ 先于 FUZZ2 匹配
+	keywords := make([]string, 0, len(payload))
+	for keyword := range payload {
+		keywords = append(keywords, keyword)
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		return len(keywords[i]) > len(keywords[j])
+	})
+	pairs := make([]string, 0, len(keywords)*2)
+	for _, keyword := range keywords {
+		pairs = append(pairs, keyword, payload[keyword])
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	headers := make(map[string]string, len(s.config.Headers))
+	for key, value := range s.config.Headers {
+		headers[replacer.Replace(key)] = replacer.Replace(value)
+	}
+
+	return &request{
+		method:   replacer.Replace(method),
+		url:      replacer.Replace(s.config.Target),
+		headers:  headers,
+		body:     s.config.RequestBody(replacer.Replace),
+		payload:  payload,
+		template: method,
+	}
+}
+
+// randomPayload 为每个词典关键字生成随机词条，用于校准模板请求
+func (s *Scanner) randomPayload() (map[string]string, error) {
+	specs := s.config.WordlistSpecs()
+	payload := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		token, err := randomToken()
+		if err != nil {
+			return nil, err
+		}
+		payload[spec.Keyword] = token
+	}
+	return payload, nil
+}
+
+// fuzz 判断是否为模板请求
+func (r *request) fuzz() bool {
+	return r.payload != nil
+}
+
+// reflected 返回可能被页面回显的部分：模板请求为各个词条，虚拟主机请求为 Host，普通请求为路径的最后一段
+func (r *request) reflected() []string {
+	if r.host != "" {
+		return []string{r.host}
+	}
+	if r.fuzz() {
+		tokens := make([]string, 0, len(r.payload))
+		for _, word := range r.payload {
+			tokens = append(tokens, word)
+		}
+		return tokens
+	}
+	return []string{lastSegment(r.url)}
+}
+
+// build 创建 http.Request，每次重试都需要重新创建以重置请求体
+func (r *request) build(ctx context.Context) (*http.Request, error) {
+	var body io.Reader
+	if r.body != "" {
+		body = strings.NewReader(r.body)
+	}
+	return http.NewRequestWithContext(ctx, r.method, r.url, body)
+}
@@ -0,0 +1,128 @@
+package scanner
+
+import (
+	"maps"
+	"testing"
+
+	"dirsearch-go/pkg/config"
+)
+
+func TestFuzzRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      config.Config
+		method      string
+		payload     map[string]string
+		wantMethod  string
+		wantURL     string
+		wantHeaders map[string]string
+		wantBody    string
+	}{
+		{
+			name:        "路径",
+			config:      config.Config{Target: "https://example.com/FUZZ"},
+			method:      "GET",
+			payload:     map[string]string{"FUZZ": "admin"},
+			wantMethod:  "GET",
+			wantURL:     "https://example.com/admin",
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:        "主机和查询参数",
+			config:      config.Config{Target: "https://FUZZ.example.com/?id=FUZZ&page=1"},
+			method:      "GET",
+			payload:     map[string]string{"FUZZ": "dev"},
+			wantMethod:  "GET",
+			wantURL:     "https://dev.example.com/?id=dev&page=1",
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:        "较长的关键字优先替换",
+			config:      config.Config{Target: "https://example.com/FUZZ/FUZZ2"},
+			method:      "GET",
+			payload:     map[string]string{"FUZZ": "a", "FUZZ2": "b"},
+			wantMethod:  "GET",
+			wantURL:     "https://example.com/a/b",
+			wantHeaders: map[string]string{},
+		},
+		{
+			name: "请求头的名称和值",
+			config: config.Config{
+				Target:  "https://example.com/",
+				Headers: map[string]string{"X-FUZZ": "1", "Authorization": "Bearer FUZZ", "Accept": "*/*"},
+			},
+			method:      "GET",
+			payload:     map[string]string{"FUZZ": "token"},
+			wantMethod:  "GET",
+			wantURL:     "https://example.com/",
+			wantHeaders: map[string]string{"X-token": "1", "Authorization": "Bearer token", "Accept": "*/*"},
+		},
+		{
+			name:        "请求方法",
+			config:      config.Config{Target: "https://example.com/"},
+			method:      "FUZZ",
+			payload:     map[string]string{"FUZZ": "PUT"},
+			wantMethod:  "PUT",
+			wantURL:     "https://example.com/",
+			wantHeaders: map[string]string{},
+		},
+		{
+			name:        "请求体",
+			config:      config.Config{Target: "https://example.com/login", Data: "user=USER&pass=PASS"},
+			method:      "POST",
+			payload:     map[string]string{"USER": "admin", "PASS": "123456"},
+			wantMethod:  "POST",
+			wantURL:     "https://example.com/login",
+			wantHeaders: map[string]string{},
+			wantBody:    "user=admin&pass=123456",
+		},
+		{
+			name:        "表单字段在替换后编码",
+			config:      config.Config{Target: "https://example.com/login", Form: map[string]string{"user": "FUZZ"}},
+			method:      "POST",
+			payload:     map[string]string{"FUZZ": "a&b"},
+			wantMethod:  "POST",
+			wantURL:     "https://example.com/login",
+			wantHeaders: map[string]string{},
+			wantBody:    "user=a%26b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := maps.Clone(tt.config.Headers)
+			s := &Scanner{config: &tt.config}
+
+			req := s.fuzzRequest(tt.method, tt.payload)
+			if req.method != tt.wantMethod {
+				t.Errorf("method = %q, want %q", req.method, tt.wantMethod)
+			}
+			if req.url != tt.wantURL {
+				t.Errorf("url = %q, want %q", req.url, tt.wantURL)
+			}
+			if !maps.Equal(req.headers, tt.wantHeaders) {
+				t.Errorf("headers = %v, want %v", req.headers, tt.wantHeaders)
+			}
+			if req.body != tt.wantBody {
+				t.Errorf("body = %q, want %q", req.body, tt.wantBody)
+			}
+			if !req.fuzz() || req.template != tt.method {
+				t.Errorf("template = %q, want %q", req.template, tt.method)
+			}
+			if !maps.Equal(tt.config.Headers, headers) {
+				t.Errorf("配置中的请求头被修改: %v", tt.config.Headers)
+			}
+		})
+	}
+}
+
+func TestNewRequestKeepsKeywords(t *testing.T) {
+	s := &Scanner{config: &config.Config{Data: "q=FUZZ", Headers: map[string]string{"X-Test": "FUZZ"}}}
+	req := s.newRequest("POST", "https://example.com/FUZZ")
+	if req.url != "https://example.com/FUZZ" || req.body != "q=FUZZ" || req.headers["X-Test"] != "FUZZ" {
+		t.Errorf("普通请求替换了关键字: %+v", req)
+	}
+	if req.fuzz() {
+		t.Error("普通请求被识别为模板请求")
+	}
+}
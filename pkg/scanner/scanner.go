@@ -2,22 +2,29 @@ package scanner
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
+	"dirsearch-go/pkg/auth"
 	"dirsearch-go/pkg/config"
+	"dirsearch-go/pkg/cookies"
 	"dirsearch-go/pkg/logger"
+	"dirsearch-go/pkg/proxy"
+	"dirsearch-go/pkg/scheduler"
+	"dirsearch-go/pkg/useragent"
 )
 
 // Result 扫描结果
 type Result struct {
+	Target      string            `json:"target,omitempty"` // 所属的扫描目标
 	URL         string            `json:"url"`
+	Host        string            `json:"host,omitempty"` // 虚拟主机扫描时发送的 Host 请求头
 	StatusCode  int               `json:"status_code"`
 	Size        int64             `json:"size"`
 	Headers     map[string]string `json:"headers,omitempty"`
@@ -25,43 +32,105 @@ type Result struct {
 	Error       string            `json:"error,omitempty"`
 	Depth       int               `json:"depth"`
 	Method      string            `json:"method"`
+	Words       int               `json:"words"`
+	Lines       int               `json:"lines"`
+	Location    string            `json:"location,omitempty"`
+	Source      string            `json:"source,omitempty"`       // 发现方式：wordlist, crawl, js
+	Payload     map[string]string `json:"payload,omitempty"`      // 模板模式下代入各关键字的词条
+	DerivedFrom string            `json:"derived_from,omitempty"` // 衍生出该结果的命中URL，例如备份文件对应的原文件
+	RequestBody string            `json:"request_body,omitempty"` // 发送的请求体（仅详细模式），用于复现
+	UserAgent   string            `json:"user_agent,omitempty"`   // 随机选择的用户代理（仅详细模式）
+	Protocol    string            `json:"protocol,omitempty"`     // 实际使用的协议版本，例如 HTTP/1.1、HTTP/2.0
+	Calibration bool              `json:"calibration,omitempty"`  // 校准探测结果，不是真实发现
+	Hidden      bool              `json:"-"`                      // 被状态码过滤的目录（例如默认排除的 403），只用于目录递归，不输出
 	Timestamp   time.Time         `json:"timestamp"`
+
+	fingerprint Fingerprint       // 软404比对使用的指纹
+	body        []byte            // 原始响应体，用于提取链接
+	contentType string            // 响应的 Content-Type
+	fuzz        bool              // 是否为 FUZZ 模板请求的结果
+	template    string            // 模板中的请求方法，用于查找 FUZZ 模式的基线
+	agent       useragent.Profile // 请求使用的浏览器特征，重放时保持一致
+}
+
+// DiscardBody 释放原始响应体，链接提取等使用完毕后调用，避免输出缓冲中的结果占用内存
+// 详细模式下输出的 Body 字段不受影响
+func (r *Result) DiscardBody() {
+	r.body = nil
 }
 
 // Scanner 扫描器
 type Scanner struct {
-	config        *config.Config
-	client        *http.Client
-	logger        *logger.Logger
-	includeRegex  *regexp.Regexp
-	excludeRegex  *regexp.Regexp
-	rateLimiter   chan struct{}
+	config       *config.Config
+	client       *http.Client
+	logger       *logger.Logger
+	includeRegex *regexp.Regexp
+	excludeRegex *regexp.Regexp
+	proxies      *proxy.Rotator     // 代理轮换，未配置代理时为 nil
+	replayClient *http.Client       // 通过重放代理再次发送命中请求，未配置时为 nil
+	session      *session           // 登录会话，未配置登录时为 nil
+	agents       *useragent.Picker  // 随机用户代理，未启用时为 nil
+	limiter      *scheduler.Limiter // 所有扫描器共用的并发和速率限制，为 nil 时不限制
+	identities   chan *identity     // 按连接轮换用户代理时的空闲连接，否则为 nil
+	target       *url.URL           // 目标地址，用于判断链接是否同源
+	scopeInclude []*regexp.Regexp   // 递归允许跟随的URL
+	scopeExclude []*regexp.Regexp   // 递归禁止跟随的URL
+
+	calibrationMu sync.Mutex
+	calibrations  map[string]*calibration // 软404基线，键为目录前缀
 }
 
-// New 创建新的扫描器
-func New(cfg *config.Config, log *logger.Logger) (*Scanner, error) {
+// New 创建新的扫描器，jar 为所有扫描器共用的 Cookie 罐，为 nil 时不保存响应中的 Cookie
+// limiter 限制所有扫描器发出请求的并发数和速率，为 nil 时不限制
+func New(cfg *config.Config, log *logger.Logger, jar *cookies.Jar, limiter *scheduler.Limiter) (*Scanner, error) {
 	scanner := &Scanner{
-		config: cfg,
-		logger: log,
+		config:       cfg,
+		logger:       log,
+		limiter:      limiter,
+		calibrations: make(map[string]*calibration),
 	}
 
 	// 创建HTTP客户端
-	scanner.client = &http.Client{
-		Timeout: time.Duration(cfg.Timeout),
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: cfg.Scanner.SkipSSLVerify,
-			},
-			MaxIdleConns:        cfg.Threads,
-			MaxIdleConnsPerHost: cfg.Threads,
-			IdleConnTimeout:     30 * time.Second,
-		},
+	tlsCfg, err := tlsConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport := &http.Transport{
+		TLSClientConfig:     tlsCfg,
+		MaxIdleConns:        cfg.Threads,
+		MaxIdleConnsPerHost: cfg.Threads,
+		IdleConnTimeout:     30 * time.Second,
+		Protocols:           protocols(cfg.Scanner.Protocol),
+	}
+	if transport.DialContext, err = newDialer(cfg); err != nil {
+		return nil, err
 	}
 
-	// 设置重定向策略
-	if !cfg.Scanner.FollowRedirects {
-		scanner.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
+	// 配置代理，HTTP 代理和 SOCKS5 代理的认证信息由 Transport 根据地址中的用户名和密码处理
+	if proxies := cfg.ProxyURLs(); len(proxies) > 0 {
+		var err error
+		scanner.proxies, err = proxy.New(proxies, cfg.Proxy.Rotation, cfg.Proxy.MaxFailures)
+		if err != nil {
+			return nil, fmt.Errorf("配置代理失败: %w", err)
+		}
+		transport.Proxy = scanner.proxies.Proxy
+	}
+
+	if scanner.client, err = scanner.newClient(transport, jar); err != nil {
+		return nil, err
+	}
+
+	// 随机用户代理，按连接轮换时每个连接使用单独的客户端
+	if cfg.RandomAgent.Enabled {
+		if err := scanner.setupAgents(transport, jar); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Proxy.Replay != "" {
+		scanner.replayClient, err = newReplayClient(cfg, jar)
+		if err != nil {
+			return nil, fmt.Errorf("配置重放代理失败: %w", err)
 		}
 	}
 
@@ -82,39 +151,115 @@ func New(cfg *config.Config, log *logger.Logger) (*Scanner, error) {
 		}
 	}
 
-	// 创建速率限制器
-	if cfg.RateLimit.Enabled {
-		scanner.rateLimiter = make(chan struct{}, cfg.RateLimit.RequestsPerSecond)
-		go scanner.fillRateLimiter()
+	// 解析目标地址和递归范围
+	target, err := url.Parse(cfg.Target)
+	if err != nil {
+		return nil, fmt.Errorf("解析目标URL失败: %w", err)
+	}
+	scanner.target = target
+
+	if scanner.session, err = newSession(cfg.Login); err != nil {
+		return nil, fmt.Errorf("配置登录失败: %w", err)
+	}
+
+	// 初始 Cookie 加入罐中，之后可以被服务器更新
+	if jar != nil && cfg.Cookies.Value != "" {
+		if err := jar.Set(target, cfg.Cookies.Value); err != nil {
+			return nil, fmt.Errorf("解析Cookie失败: %w", err)
+		}
+	}
+
+	if scanner.scopeInclude, err = compilePatterns(cfg.Recursion.ScopeInclude); err != nil {
+		return nil, fmt.Errorf("编译递归范围正则表达式失败: %w", err)
+	}
+	if scanner.scopeExclude, err = compilePatterns(cfg.Recursion.ScopeExclude); err != nil {
+		return nil, fmt.Errorf("编译递归排除正则表达式失败: %w", err)
 	}
 
 	return scanner, nil
 }
 
-// fillRateLimiter 填充速率限制器
-func (s *Scanner) fillRateLimiter() {
-	ticker := time.NewTicker(time.Second / time.Duration(s.config.RateLimit.RequestsPerSecond))
-	defer ticker.Stop()
+// protocols 根据配置的协议版本返回 Transport 允许的协议
+// 自定义 TLSClientConfig 会关闭 Transport 默认的 HTTP/2 支持，因此需要显式指定
+func protocols(protocol string) *http.Protocols {
+	p := new(http.Protocols)
+	switch protocol {
+	case config.ProtocolHTTP1:
+		p.SetHTTP1(true)
+	case config.ProtocolHTTP2:
+		// 同时允许 HTTP/1 时明文请求会使用 HTTP/1，因此只开启 HTTP/2 和 h2c
+		p.SetHTTP2(true)
+		p.SetUnencryptedHTTP2(true)
+	default:
+		p.SetHTTP1(true)
+		p.SetHTTP2(true)
+	}
+	return p
+}
+
+// newClient 基于 transport 创建扫描使用的HTTP客户端，配置 Cookie 罐、认证和重定向策略
+func (s *Scanner) newClient(transport *http.Transport, jar *cookies.Jar) (*http.Client, error) {
+	client := &http.Client{
+		Timeout:   time.Duration(s.config.Timeout),
+		Transport: transport,
+	}
+	if jar != nil {
+		client.Jar = jar
+	}
 
-	for range ticker.C {
-		select {
-		case s.rateLimiter <- struct{}{}:
-		default:
+	// 配置认证：Basic 和 Bearer 直接添加请求头，Digest 和 NTLM 在 Transport 中完成质询应答
+	if s.config.Auth.Type != "" {
+		var err error
+		client.Transport, err = auth.New(credentials(s.config), transport)
+		if err != nil {
+			return nil, fmt.Errorf("配置认证失败: %w", err)
+		}
+	}
+
+	// 设置重定向策略
+	if !s.config.Scanner.FollowRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
 		}
 	}
+	return client, nil
 }
 
-// ScanURL 扫描单个URL
-func (s *Scanner) ScanURL(ctx context.Context, targetURL, path string, depth int) (*Result, error) {
-	// 速率限制
-	if s.rateLimiter != nil {
-		select {
-		case <-s.rateLimiter:
-		case <-ctx.Done():
-			return nil, ctx.Err()
+// credentials 根据配置生成认证信息
+func credentials(cfg *config.Config) auth.Credentials {
+	return auth.Credentials{
+		Type:     cfg.Auth.Type,
+		Username: cfg.Auth.Username,
+		Password: cfg.Auth.Password,
+		Domain:   cfg.Auth.Domain,
+		Token:    cfg.Auth.Token,
+		Scope:    authScope(cfg),
+	}
+}
+
+// authScope 返回允许发送认证信息的地址：扫描目标和登录地址，重定向到其他主机时不发送
+// 模板目标中的关键字替换为 *，以匹配代入词条后的主机
+func authScope(cfg *config.Config) []string {
+	target := cfg.Target
+	if cfg.FuzzMode() {
+		for _, spec := range cfg.WordlistSpecs() {
+			target = strings.ReplaceAll(target, spec.Keyword, "*")
+		}
+	}
+	scope := []string{target}
+
+	if cfg.Login.URL != "" {
+		if u, err := url.Parse(target); err == nil {
+			if login, err := u.Parse(cfg.Login.URL); err == nil {
+				scope = append(scope, login.String())
+			}
 		}
 	}
+	return scope
+}
 
+// ScanURL 扫描单个URL
+func (s *Scanner) ScanURL(ctx context.Context, targetURL, path string, depth int) (*Result, error) {
 	// 跳过包含占位符的路径
 	if strings.Contains(path, "%FUZZ%") {
 		return nil, nil
@@ -123,18 +268,28 @@ func (s *Scanner) ScanURL(ctx context.Context, targetURL, path string, depth int
 	// 构建完整URL
 	fullURL := strings.TrimRight(targetURL, "/") + "/" + strings.TrimLeft(path, "/")
 
-	// 尝试多种HTTP方法
+	// 尝试多种HTTP方法，只用于递归的结果在其他方法都没有命中时才返回
+	var hidden *Result
 	for _, method := range s.config.Scanner.Methods {
-		result, err := s.makeRequest(ctx, method, fullURL, depth)
-		if err != nil {
-			// 只记录非URL解析错误
-			if !strings.Contains(err.Error(), "invalid URL escape") {
-				s.logger.Debug("请求失败", "url", fullURL, "method", method, "error", err)
-			}
+		result := s.try(ctx, s.newRequest(method, fullURL), depth)
+		if result == nil {
 			continue
 		}
+		if !result.Hidden {
+			return result, nil
+		}
+		if hidden == nil {
+			hidden = result
+		}
+	}
+
+	return hidden, nil
+}
 
-		if result != nil && s.shouldIncludeResult(result) {
+// ScanFuzz 将各关键字的词条代入请求模板后发送请求
+func (s *Scanner) ScanFuzz(ctx context.Context, payload map[string]string, depth int) (*Result, error) {
+	for _, method := range s.config.Scanner.Methods {
+		if result := s.try(ctx, s.fuzzRequest(method, payload), depth); result != nil {
 			return result, nil
 		}
 	}
@@ -142,39 +297,167 @@ func (s *Scanner) ScanURL(ctx context.Context, targetURL, path string, depth int
 	return nil, nil
 }
 
-// makeRequest 发送HTTP请求
-func (s *Scanner) makeRequest(ctx context.Context, method, url string, depth int) (*Result, error) {
-	var err error
+// try 发送请求，结果通过过滤时返回，否则返回 nil
+func (s *Scanner) try(ctx context.Context, r *request, depth int) *Result {
+	result, err := s.makeRequest(ctx, r, depth)
+	if err != nil {
+		// 只记录非URL解析错误
+		if !strings.Contains(err.Error(), "invalid URL escape") {
+			s.logger.Debug("请求失败", "url", r.url, "method", r.method, "error", err)
+		}
+		return nil
+	}
+
+	if result == nil {
+		return nil
+	}
+	if s.shouldIncludeResult(result) {
+		if s.replayClient != nil && result.Error == "" {
+			s.replay(ctx, r, result.agent)
+		}
+		return result
+	}
+
+	// 只因状态码被过滤的目录仍然触发目录递归，否则默认排除的 403 永远不会到达 IsDirectory
+	if !r.fuzz() && s.dirRecursion() && s.matchesContentFilters(result) && s.IsDirectory(result) {
+		result.Hidden = true
+		return result
+	}
+	return nil
+}
+
+// prepare 根据请求模板创建HTTP请求并设置请求头，agent 为请求使用的浏览器特征
+func (s *Scanner) prepare(ctx context.Context, r *request, agent useragent.Profile) (*http.Request, error) {
+	req, err := r.build(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	// 设置请求头，自定义请求头优先
+	setAgent(req, agent)
+	for key, value := range r.headers {
+		req.Header.Set(key, value)
+	}
+	// 未启用 Cookie 罐时，初始 Cookie 作为固定的请求头发送
+	if s.client.Jar == nil && s.config.Cookies.Value != "" && req.Header.Get("Cookie") == "" {
+		req.Header.Set("Cookie", s.config.Cookies.Value)
+	}
+	// Host 请求头需要通过 req.Host 设置才会生效，虚拟主机扫描的词条优先
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+		req.Header.Del("Host")
+	}
+	if r.host != "" {
+		req.Host = r.host
+	}
+	if r.body != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", s.config.RequestContentType())
+	}
+	return req, nil
+}
+
+// do 在主机的并发和速率限制内发送请求，响应体关闭后归还并发名额
+// 扫描器的所有请求（包括校准、预扫描、登录、重试和重放）都通过这里发送
+func (s *Scanner) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	release, err := s.limiter.Acquire(req.Context(), strings.ToLower(req.URL.Host))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseBody 关闭响应体时归还并发名额，release 可以重复调用
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close 关闭响应体并归还并发名额
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// makeRequest 发送HTTP请求，配置了登录时检测会话是否失效，失效后重新登录并重发
+func (s *Scanner) makeRequest(ctx context.Context, r *request, depth int) (*Result, error) {
+	if s.session == nil {
+		return s.send(ctx, r, depth)
+	}
+
+	for attempt := 0; ; attempt++ {
+		// 重新登录期间在这里等待
+		s.session.mu.RLock()
+		generation := s.session.generation
+		result, err := s.send(ctx, r, depth)
+		s.session.mu.RUnlock()
+
+		// 多次重新登录后仍然失效，说明不是会话的问题（例如请求的就是登录页面）
+		if err != nil || attempt >= maxSessionRetries || !s.session.lost(result) {
+			return result, err
+		}
+		if !s.relogin(ctx, generation) {
+			return result, nil
+		}
+		s.logger.Debug("会话失效，重发请求", "url", r.url, "method", r.method)
+	}
+}
+
+// send 发送HTTP请求，失败时按配置重试
+func (s *Scanner) send(ctx context.Context, r *request, depth int) (*Result, error) {
+	id, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.release(id)
+
 	var resp *http.Response
 
 	// 重试机制
+retry:
 	for i := 0; i <= s.config.RetryCount; i++ {
-		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+		var req *http.Request
+		req, err = s.prepare(ctx, r, id.agent)
 		if err != nil {
-			return nil, fmt.Errorf("创建请求失败: %w", err)
+			return nil, err
 		}
 
-		// 设置请求头
-		req.Header.Set("User-Agent", s.config.UserAgent)
-		for key, value := range s.config.Headers {
-			req.Header.Set(key, value)
+		// 每次请求（包括重试）重新选择代理，并记录代理是否可用
+		var p *proxy.Proxy
+		if s.proxies != nil {
+			p = s.proxies.Next()
+			req = req.WithContext(proxy.WithProxy(req.Context(), p))
 		}
 
-		resp, err = s.client.Do(req)
+		resp, err = s.do(id.client, req)
+		if p != nil && s.proxies.Report(p, err) {
+			s.logger.Warn("代理连续失败，已停用", "proxy", p, "remaining", s.proxies.Live(), "error", err)
+		}
 		if err == nil {
 			break
 		}
 
 		if i < s.config.RetryCount {
-			s.logger.Debug("请求重试", "url", url, "attempt", i+1, "error", err)
-			time.Sleep(time.Duration(s.config.RetryDelay))
+			s.logger.Debug("请求重试", "url", r.url, "attempt", i+1, "error", err)
+			select {
+			case <-ctx.Done():
+				break retry
+			case <-time.After(time.Duration(s.config.RetryDelay)):
+			}
 		}
 	}
 
 	if err != nil {
 		return &Result{
-			URL:       url,
-			Method:    method,
+			URL:       r.url,
+			Host:      r.host,
+			Method:    r.method,
 			Error:     err.Error(),
 			Depth:     depth,
 			Timestamp: time.Now(),
@@ -183,8 +466,9 @@ func (s *Scanner) makeRequest(ctx context.Context, method, url string, depth int
 
 	if resp == nil {
 		return &Result{
-			URL:       url,
-			Method:    method,
+			URL:       r.url,
+			Host:      r.host,
+			Method:    r.method,
 			Error:     "响应为空",
 			Depth:     depth,
 			Timestamp: time.Now(),
@@ -196,18 +480,34 @@ func (s *Scanner) makeRequest(ctx context.Context, method, url string, depth int
 	// 读取响应体
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		s.logger.Error("读取响应体失败", "url", url, "error", err)
+		s.logger.Error("读取响应体失败", "url", r.url, "error", err)
 		body = []byte{}
 	}
 
 	// 构建结果
+	location := resp.Header.Get("Location")
 	result := &Result{
-		URL:        url,
-		StatusCode: resp.StatusCode,
-		Size:       int64(len(body)),
-		Method:     method,
-		Depth:      depth,
-		Timestamp:  time.Now(),
+		URL:         r.url,
+		Host:        r.host,
+		StatusCode:  resp.StatusCode,
+		Size:        int64(len(body)),
+		Method:      r.method,
+		Depth:       depth,
+		Words:       countWords(body),
+		Lines:       countLines(body),
+		Location:    location,
+		Protocol:    resp.Proto,
+		Timestamp:   time.Now(),
+		fingerprint: newFingerprint(resp.StatusCode, r.reflected(), body, location),
+		body:        body,
+		contentType: resp.Header.Get("Content-Type"),
+		fuzz:        r.fuzz(),
+		template:    r.template,
+		agent:       id.agent,
+	}
+
+	if r.fuzz() {
+		result.Payload = r.payload
 	}
 
 	// 如果需要详细输出，包含响应头和体
@@ -217,6 +517,10 @@ func (s *Scanner) makeRequest(ctx context.Context, method, url string, depth int
 			result.Headers[key] = strings.Join(values, ", ")
 		}
 		result.Body = string(body)
+		result.RequestBody = r.body
+		if s.agents != nil {
+			result.UserAgent = id.agent.UserAgent
+		}
 	}
 
 	return result, nil
@@ -224,6 +528,11 @@ func (s *Scanner) makeRequest(ctx context.Context, method, url string, depth int
 
 // shouldIncludeResult 判断是否应该包含结果
 func (s *Scanner) shouldIncludeResult(result *Result) bool {
+	return s.matchesStatusFilters(result) && s.matchesContentFilters(result)
+}
+
+// matchesStatusFilters 判断状态码是否通过包含和排除列表
+func (s *Scanner) matchesStatusFilters(result *Result) bool {
 	// 状态码过滤
 	if len(s.config.Filters.StatusCodes) > 0 {
 		included := false
@@ -244,6 +553,15 @@ func (s *Scanner) shouldIncludeResult(result *Result) bool {
 			return false
 		}
 	}
+	return true
+}
+
+// matchesContentFilters 判断结果是否通过软404基线、大小、正则和关键词过滤
+func (s *Scanner) matchesContentFilters(result *Result) bool {
+	// 软404基线过滤
+	if s.matchesBaseline(result) {
+		return false
+	}
 
 	// 大小过滤
 	if s.config.Filters.MinSize > 0 && result.Size < s.config.Filters.MinSize {
@@ -287,41 +605,21 @@ func (s *Scanner) shouldIncludeResult(result *Result) bool {
 	return true
 }
 
-// ExtractPaths 从响应中提取路径（用于递归扫描）
-func (s *Scanner) ExtractPaths(result *Result) []string {
-	if result.Body == "" {
-		return nil
-	}
-
-	// 简单的路径提取正则表达式
-	pathRegex := regexp.MustCompile(`href=["']([^"']+)["']`)
-	matches := pathRegex.FindAllStringSubmatch(result.Body, -1)
-
-	var paths []string
-	for _, match := range matches {
-		if len(match) > 1 {
-			path := match[1]
-			// 过滤掉外部链接和特殊路径
-			if !strings.HasPrefix(path, "http") && 
-			   !strings.HasPrefix(path, "mailto:") && 
-			   !strings.HasPrefix(path, "#") &&
-			   !strings.HasPrefix(path, "javascript:") {
-				
-				// 解析URL
-				u, err := url.Parse(path)
-				if err == nil && u.Path != "" {
-					paths = append(paths, strings.TrimPrefix(u.Path, "/"))
-				}
+// Close 关闭扫描器
+func (s *Scanner) Close() {
+	s.client.CloseIdleConnections()
+	if s.identities != nil {
+	drain:
+		for {
+			select {
+			case id := <-s.identities:
+				id.client.CloseIdleConnections()
+			default:
+				break drain
 			}
 		}
 	}
-
-	return paths
-}
-
-// Close 关闭扫描器
-func (s *Scanner) Close() {
-	if s.rateLimiter != nil {
-		close(s.rateLimiter)
+	if s.replayClient != nil {
+		s.replayClient.CloseIdleConnections()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,37 @@
+package scanner
+
+import (
+	"slices"
+	"testing"
+
+	"dirsearch-go/pkg/config"
+)
+
+func TestAuthScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		config config.Config
+		want   []string
+	}{
+		{"目标", config.Config{Target: "https://example.com/app/"}, []string{"https://example.com/app/"}},
+		{
+			"相对目标的登录地址",
+			config.Config{Target: "https://example.com/app/", Login: config.LoginConfig{URL: "/login"}},
+			[]string{"https://example.com/app/", "https://example.com/login"},
+		},
+		{
+			"其他主机的登录地址",
+			config.Config{Target: "https://example.com/", Login: config.LoginConfig{URL: "https://sso.example.com/login"}},
+			[]string{"https://example.com/", "https://sso.example.com/login"},
+		},
+		{"模板中的关键字", config.Config{Target: "https://FUZZ.example.com/FUZZ"}, []string{"https://*.example.com/*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := authScope(&tt.config); !slices.Equal(got, tt.want) {
+				t.Errorf("authScope() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
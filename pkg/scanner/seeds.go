@@ -0,0 +1,195 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// 预扫描阶段发现的结果来源
+const (
+	SourceRobots      = "robots"       // robots.txt 中的 Allow/Disallow
+	SourceSitemap     = "sitemap"      // sitemap 中的 <loc>
+	SourceSecurityTxt = "security.txt" // security.txt 中的链接
+)
+
+// maxSitemaps 最多解析的 sitemap 数量，防止 sitemap 索引互相引用或数量过多
+const maxSitemaps = 50
+
+// maxSitemapSize 解压后 sitemap 的最大大小
+const maxSitemapSize = 50 << 20
+
+// sitemapDocument 同时兼容 <urlset> 和 <sitemapindex>
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// DiscoverSeeds 读取目标站点的 robots.txt、sitemap.xml 和 security.txt，返回其中与目标同源的路径
+// 这些文件本身也会作为种子返回，以便在结果中体现是否存在
+func (s *Scanner) DiscoverSeeds(ctx context.Context) []Link {
+	origin := &url.URL{Scheme: s.target.Scheme, Host: s.target.Host, Path: "/"}
+	c := &crawl{scanner: s, seen: make(map[string]bool)}
+
+	sitemaps := []string{origin.ResolveReference(&url.URL{Path: "/sitemap.xml"}).String()}
+
+	// robots.txt
+	robotsURL := origin.ResolveReference(&url.URL{Path: "/robots.txt"})
+	if body, ok := s.fetchSeed(ctx, robotsURL.String()); ok {
+		c.add(origin, robotsURL.String(), SourceRobots)
+		entries, declared := parseRobots(body)
+		for _, entry := range entries {
+			c.add(origin, entry, SourceRobots)
+		}
+		sitemaps = append(sitemaps, declared...)
+		s.logger.Info("已解析 robots.txt", "paths", len(entries), "sitemaps", len(declared))
+	}
+
+	// sitemap.xml 以及 robots.txt 中声明的 sitemap，包括嵌套的 sitemap 索引
+	visited := make(map[string]bool)
+	for len(sitemaps) > 0 && len(visited) < maxSitemaps {
+		if ctx.Err() != nil {
+			return c.links
+		}
+
+		current := sitemaps[0]
+		sitemaps = sitemaps[1:]
+		sitemapURL, err := origin.Parse(current)
+		if err != nil || visited[sitemapURL.String()] || !sameOrigin(sitemapURL, s.target) {
+			continue
+		}
+		visited[sitemapURL.String()] = true
+
+		body, ok := s.fetchSeed(ctx, sitemapURL.String())
+		if !ok {
+			continue
+		}
+		doc, err := parseSitemap(body)
+		if err != nil {
+			s.logger.Debug("解析 sitemap 失败", "url", sitemapURL.String(), "error", err)
+			continue
+		}
+
+		c.add(origin, sitemapURL.String(), SourceSitemap)
+		for _, loc := range doc.URLs {
+			c.add(sitemapURL, loc.Loc, SourceSitemap)
+		}
+		for _, nested := range doc.Sitemaps {
+			sitemaps = append(sitemaps, strings.TrimSpace(nested.Loc))
+		}
+		s.logger.Info("已解析 sitemap", "url", sitemapURL.String(), "urls", len(doc.URLs), "sitemaps", len(doc.Sitemaps))
+	}
+
+	// security.txt
+	for _, p := range []string{"/.well-known/security.txt", "/security.txt"} {
+		securityURL := origin.ResolveReference(&url.URL{Path: p})
+		body, ok := s.fetchSeed(ctx, securityURL.String())
+		if !ok {
+			continue
+		}
+		c.add(origin, securityURL.String(), SourceSecurityTxt)
+		for _, link := range parseSecurityTxt(body) {
+			c.add(securityURL, link, SourceSecurityTxt)
+		}
+		break
+	}
+
+	return c.links
+}
+
+// fetchSeed 获取预扫描文件，只接受 2xx 且不是软404的响应
+func (s *Scanner) fetchSeed(ctx context.Context, rawURL string) ([]byte, bool) {
+	result, err := s.makeRequest(ctx, s.newRequest("GET", rawURL), 0)
+	if err != nil || result.Error != "" {
+		return nil, false
+	}
+	if result.StatusCode < 200 || result.StatusCode >= 300 || s.matchesBaseline(result) {
+		return nil, false
+	}
+	return result.body, true
+}
+
+// parseRobots 解析 robots.txt，返回 Allow/Disallow 路径和声明的 sitemap 地址
+// 通配符之后的部分会被截断，只保留可以直接请求的前缀
+func parseRobots(body []byte) (paths []string, sitemaps []string) {
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "allow", "disallow":
+			if i := strings.IndexAny(value, "*$"); i >= 0 {
+				value = value[:i]
+			}
+			if value != "" && value != "/" {
+				paths = append(paths, value)
+			}
+		case "sitemap":
+			if value != "" {
+				sitemaps = append(sitemaps, value)
+			}
+		}
+	}
+	return paths, sitemaps
+}
+
+// parseSitemap 解析 sitemap 或 sitemap 索引，自动解压 gzip 压缩的 sitemap
+func parseSitemap(body []byte) (*sitemapDocument, error) {
+	if len(body) > 2 && body[0] == 0x1f && body[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		body, err = io.ReadAll(io.LimitReader(reader, maxSitemapSize))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var doc sitemapDocument
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// parseSecurityTxt 提取 security.txt 中字段值为URL的条目
+func parseSecurityTxt(body []byte) []string {
+	var links []string
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		_, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "/") {
+			links = append(links, value)
+		}
+	}
+	return links
+}
@@ -0,0 +1,123 @@
+package scanner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"slices"
+	"testing"
+)
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		wantPaths    []string
+		wantSitemaps []string
+	}{
+		{
+			name:      "Allow 和 Disallow",
+			body:      "User-agent: *\nDisallow: /admin/\nAllow: /public\n",
+			wantPaths: []string{"/admin/", "/public"},
+		},
+		{
+			name:      "忽略注释和根路径",
+			body:      "# comment\nDisallow: /\nDisallow: /tmp # 临时文件\n",
+			wantPaths: []string{"/tmp"},
+		},
+		{
+			name:      "截断通配符",
+			body:      "Disallow: /search*\nDisallow: /*.php$\nDisallow: /api/v1$\n",
+			wantPaths: []string{"/search", "/api/v1"},
+		},
+		{
+			name:         "声明的 sitemap",
+			body:         "Sitemap: https://example.com/sitemap_index.xml\nsitemap:/news.xml\n",
+			wantSitemaps: []string{"https://example.com/sitemap_index.xml", "/news.xml"},
+		},
+		{
+			name:      "字段名不区分大小写",
+			body:      "DISALLOW: /private\n",
+			wantPaths: []string{"/private"},
+		},
+		{
+			name: "空值和无效行",
+			body: "Disallow:\nnot a directive\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, sitemaps := parseRobots([]byte(tt.body))
+			if !slices.Equal(paths, tt.wantPaths) {
+				t.Errorf("paths = %q, want %q", paths, tt.wantPaths)
+			}
+			if !slices.Equal(sitemaps, tt.wantSitemaps) {
+				t.Errorf("sitemaps = %q, want %q", sitemaps, tt.wantSitemaps)
+			}
+		})
+	}
+}
+
+func TestParseSitemap(t *testing.T) {
+	urlset := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/a</loc></url>
+  <url><loc>https://example.com/b/c</loc></url>
+</urlset>`
+	index := `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/sitemap1.xml</loc></sitemap>
+</sitemapindex>`
+
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	w.Write([]byte(urlset))
+	w.Close()
+
+	tests := []struct {
+		name         string
+		body         []byte
+		wantURLs     []string
+		wantSitemaps []string
+		wantErr      bool
+	}{
+		{"urlset", []byte(urlset), []string{"https://example.com/a", "https://example.com/b/c"}, nil, false},
+		{"sitemap 索引", []byte(index), nil, []string{"https://example.com/sitemap1.xml"}, false},
+		{"gzip 压缩", compressed.Bytes(), []string{"https://example.com/a", "https://example.com/b/c"}, nil, false},
+		{"无效的XML", []byte("<urlset><url>"), nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseSitemap(tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSitemap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := locs(doc.URLs); !slices.Equal(got, tt.wantURLs) {
+				t.Errorf("URLs = %q, want %q", got, tt.wantURLs)
+			}
+			if got := locs(doc.Sitemaps); !slices.Equal(got, tt.wantSitemaps) {
+				t.Errorf("Sitemaps = %q, want %q", got, tt.wantSitemaps)
+			}
+		})
+	}
+}
+
+func TestParseSecurityTxt(t *testing.T) {
+	body := "# comment\nContact: mailto:security@example.com\nContact: https://example.com/security\nPolicy: /policy.html\nExpires: 2030-01-01T00:00:00Z\n"
+	want := []string{"https://example.com/security", "/policy.html"}
+	if got := parseSecurityTxt([]byte(body)); !slices.Equal(got, want) {
+		t.Errorf("parseSecurityTxt() = %q, want %q", got, want)
+	}
+}
+
+// locs 取出 sitemap 条目中的地址
+func locs(entries []sitemapLoc) []string {
+	var urls []string
+	for _, entry := range entries {
+		urls = append(urls, entry.Loc)
+	}
+	return urls
+}
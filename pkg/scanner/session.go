@@ -0,0 +1,198 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"dirsearch-go/pkg/config"
+)
+
+const (
+	// maxLoginFailures 连续重新登录失败达到该次数后不再尝试，避免每个请求都触发登录
+	maxLoginFailures = 3
+	// maxSessionRetries 每个请求因会话失效最多重发的次数
+	maxSessionRetries = 2
+)
+
+// session 登录会话
+// 每个请求在发送期间持有读锁；重新登录持有写锁，等待进行中的请求结束并暂停新的请求，登录完成后再继续
+type session struct {
+	config       config.LoginConfig
+	successRegex *regexp.Regexp
+	lostLocation *regexp.Regexp
+	lostRegex    *regexp.Regexp
+
+	mu         sync.RWMutex
+	generation int // 成功登录的次数，用于判断会话是否已被其他线程重新建立
+	failures   int // 连续登录失败的次数
+}
+
+// newSession 根据登录配置创建会话，未配置登录时返回 nil
+func newSession(cfg config.LoginConfig) (*session, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	s := &session{config: cfg}
+	for _, p := range []struct {
+		pattern string
+		regex   **regexp.Regexp
+	}{
+		{cfg.SuccessRegex, &s.successRegex},
+		{cfg.LostLocation, &s.lostLocation},
+		{cfg.LostRegex, &s.lostRegex},
+	} {
+		if p.pattern == "" {
+			continue
+		}
+		regex, err := regexp.Compile(p.pattern)
+		if err != nil {
+			return nil, err
+		}
+		*p.regex = regex
+	}
+	return s, nil
+}
+
+// lost 判断响应是否表明会话已失效，未配置失效条件时始终为 false
+func (s *session) lost(result *Result) bool {
+	if result.Error != "" {
+		return false
+	}
+	if slices.Contains(s.config.LostStatus, result.StatusCode) {
+		return true
+	}
+	if s.lostLocation != nil && result.Location != "" && s.lostLocation.MatchString(result.Location) {
+		return true
+	}
+	return s.lostRegex != nil && s.lostRegex.Match(result.body)
+}
+
+// Login 发送登录请求建立会话，在扫描目标之前调用
+func (s *Scanner) Login(ctx context.Context) error {
+	if s.session == nil {
+		return nil
+	}
+
+	s.session.mu.Lock()
+	defer s.session.mu.Unlock()
+	if err := s.login(ctx); err != nil {
+		return err
+	}
+	s.session.generation++
+	return nil
+}
+
+// relogin 会话失效后重新登录，generation 为失效请求发出时的会话
+// 其他线程已经重新登录时直接返回 true，由调用方重发请求
+func (s *Scanner) relogin(ctx context.Context, generation int) bool {
+	s.session.mu.Lock()
+	defer s.session.mu.Unlock()
+
+	if s.session.generation != generation {
+		return true
+	}
+	if s.session.failures >= maxLoginFailures || ctx.Err() != nil {
+		return false
+	}
+
+	if err := s.login(ctx); err != nil {
+		s.session.failures++
+		s.logger.Error("重新登录失败", "target", s.config.Target, "error", err)
+		if s.session.failures == maxLoginFailures {
+			s.logger.Error("重新登录连续失败，不再尝试", "target", s.config.Target, "failures", s.session.failures)
+		}
+		return false
+	}
+	s.session.failures = 0
+	s.session.generation++
+	s.logger.Info("会话已失效，已重新登录", "target", s.config.Target)
+	return true
+}
+
+// login 发送登录请求并检查登录是否成功，会话 Cookie 由客户端的 Cookie 罐保存
+func (s *Scanner) login(ctx context.Context) error {
+	cfg := s.session.config
+	loginURL, err := s.target.Parse(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("解析登录地址失败: %w", err)
+	}
+
+	body := cfg.Body()
+	req, err := http.NewRequestWithContext(ctx, cfg.Method, loginURL.String(), strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建登录请求失败: %w", err)
+	}
+	setAgent(req, s.agent())
+	if body != "" {
+		req.Header.Set("Content-Type", cfg.RequestContentType())
+	}
+	for key, value := range cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	// 重新登录时罐中可能还有失效的会话 Cookie，记录下来以判断服务器是否设置了新的值
+	previous := s.cookie(loginURL, cfg.SuccessCookie)
+
+	resp, err := s.do(s.client, req)
+	if err != nil {
+		return fmt.Errorf("发送登录请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取登录响应失败: %w", err)
+	}
+	s.logger.Debug("已发送登录请求", "url", loginURL, "status", resp.StatusCode)
+
+	return s.checkLogin(loginURL, resp, data, previous)
+}
+
+// checkLogin 检查登录响应是否满足所有配置的成功条件
+func (s *Scanner) checkLogin(loginURL *url.URL, resp *http.Response, body []byte, previous string) error {
+	cfg := s.session.config
+
+	if len(cfg.SuccessStatus) > 0 {
+		if !slices.Contains(cfg.SuccessStatus, resp.StatusCode) {
+			return fmt.Errorf("登录失败: 状态码 %d", resp.StatusCode)
+		}
+	} else if resp.StatusCode >= 400 {
+		return fmt.Errorf("登录失败: 状态码 %d", resp.StatusCode)
+	}
+
+	if s.session.successRegex != nil {
+		var head strings.Builder
+		resp.Header.Write(&head)
+		if !s.session.successRegex.MatchString(head.String()) && !s.session.successRegex.Match(body) {
+			return fmt.Errorf("登录失败: 响应不匹配 %s", cfg.SuccessRegex)
+		}
+	}
+
+	if cfg.SuccessCookie != "" {
+		set := slices.ContainsFunc(resp.Cookies(), func(c *http.Cookie) bool { return c.Name == cfg.SuccessCookie })
+		if value := s.cookie(loginURL, cfg.SuccessCookie); !set && (value == "" || value == previous) {
+			return fmt.Errorf("登录失败: 没有收到 Cookie %s", cfg.SuccessCookie)
+		}
+	}
+	return nil
+}
+
+// cookie 返回 Cookie 罐中发送给 u 的指定 Cookie 的值
+func (s *Scanner) cookie(u *url.URL, name string) string {
+	if s.client.Jar == nil || name == "" {
+		return ""
+	}
+	for _, c := range s.client.Jar.Cookies(u) {
+		if c.Name == name {
+			return c.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,271 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"dirsearch-go/pkg/config"
+	"dirsearch-go/pkg/cookies"
+	"dirsearch-go/pkg/logger"
+)
+
+// loginServer 登录后签发会话 Cookie，第一个会话处理 expireAfter 个请求后失效，之后的会话不再失效
+type loginServer struct {
+	*httptest.Server
+	expireAfter int
+
+	mu       sync.Mutex
+	session  string         // 当前有效的会话，为空表示已失效
+	logins   int            // 登录次数
+	requests int            // 当前会话已处理的请求数
+	hits     map[string]int // 每个路径收到的请求数
+}
+
+func newLoginServer(expireAfter int) *loginServer {
+	s := &loginServer{expireAfter: expireAfter, hits: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *loginServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/login" {
+		s.logins++
+		s.session = fmt.Sprintf("session-%d", s.logins)
+		s.requests = 0
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: s.session, Path: "/"})
+		fmt.Fprint(w, "welcome")
+		return
+	}
+
+	s.hits[r.URL.Path]++
+	c, err := r.Cookie("sid")
+	if err != nil || c.Value != s.session || r.URL.Path == "/always-lost" {
+		http.Redirect(w, r, "/login?expired=1", http.StatusFound)
+		return
+	}
+	s.requests++
+	if s.logins == 1 && s.requests >= s.expireAfter {
+		s.session = ""
+	}
+	fmt.Fprint(w, "ok")
+}
+
+// counts 返回登录次数和路径收到的请求数
+func (s *loginServer) counts(path string) (logins, hits int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logins, s.hits[path]
+}
+
+// newLoginScanner 创建配置了登录的扫描器
+func newLoginScanner(t *testing.T, target string, login config.LoginConfig) *Scanner {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Target = target
+	cfg.Login = login
+
+	log, err := logger.New(logger.LevelError, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(cfg, log, cookies.New(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+var testLogin = config.LoginConfig{
+	URL:           "/login",
+	Method:        "POST",
+	Data:          "user=admin&pass=admin",
+	SuccessCookie: "sid",
+	LostLocation:  `/login\?expired`,
+}
+
+func TestRelogin(t *testing.T) {
+	srv := newLoginServer(2)
+	defer srv.Close()
+	s := newLoginScanner(t, srv.URL+"/", testLogin)
+	ctx := context.Background()
+
+	if err := s.Login(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/a", "/b"} {
+		result, err := s.makeRequest(ctx, s.newRequest("GET", srv.URL+path), 0)
+		if err != nil || result.StatusCode != http.StatusOK {
+			t.Fatalf("会话有效时 %s 的结果 = %+v, %v", path, result, err)
+		}
+	}
+
+	// 第一个会话已失效，并发的请求都会检测到失效，但只应重新登录一次
+	const workers = 8
+	paths := make([]string, workers)
+	results := make([]*Result, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		paths[i] = fmt.Sprintf("/page%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := s.makeRequest(ctx, s.newRequest("GET", srv.URL+paths[i]), 0)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	retried := 0
+	for i, path := range paths {
+		if results[i] == nil || results[i].StatusCode != http.StatusOK {
+			t.Errorf("%s 的结果 = %+v, want 重新登录后的 200", path, results[i])
+		}
+		if _, hits := srv.counts(path); hits == 2 {
+			retried++
+		} else if hits != 1 {
+			t.Errorf("%s 收到 %d 个请求, want 1 或 2", path, hits)
+		}
+	}
+	if retried == 0 {
+		t.Error("会话失效的请求没有重发")
+	}
+	if logins, _ := srv.counts("/"); logins != 2 {
+		t.Errorf("登录次数 = %d, want 2", logins)
+	}
+}
+
+func TestReloginRetryLimit(t *testing.T) {
+	srv := newLoginServer(100)
+	defer srv.Close()
+	s := newLoginScanner(t, srv.URL+"/", testLogin)
+	ctx := context.Background()
+
+	if err := s.Login(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// 重新登录后仍然失效的请求最多重发 maxSessionRetries 次，返回最后一次的响应
+	result, err := s.makeRequest(ctx, s.newRequest("GET", srv.URL+"/always-lost"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.StatusCode != http.StatusFound {
+		t.Errorf("状态码 = %d, want %d", result.StatusCode, http.StatusFound)
+	}
+	logins, hits := srv.counts("/always-lost")
+	if hits != maxSessionRetries+1 {
+		t.Errorf("请求次数 = %d, want %d", hits, maxSessionRetries+1)
+	}
+	if logins != maxSessionRetries+1 {
+		t.Errorf("登录次数 = %d, want %d", logins, maxSessionRetries+1)
+	}
+}
+
+func TestSessionLost(t *testing.T) {
+	session, err := newSession(config.LoginConfig{
+		URL:          "/login",
+		LostStatus:   []int{401},
+		LostLocation: `/login`,
+		LostRegex:    `(?i)please log in`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		result *Result
+		want   bool
+	}{
+		{"正常响应", &Result{StatusCode: 200, body: []byte("dashboard")}, false},
+		{"失效状态码", &Result{StatusCode: 401}, true},
+		{"重定向到登录页", &Result{StatusCode: 302, Location: "/login?next=/admin"}, true},
+		{"重定向到其他页面", &Result{StatusCode: 302, Location: "/admin/"}, false},
+		{"响应体提示登录", &Result{StatusCode: 200, body: []byte("<p>Please log in</p>")}, true},
+		{"请求失败", &Result{Error: "timeout", StatusCode: 401}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := session.lost(tt.result); got != tt.want {
+				t.Errorf("lost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 未配置失效条件时不会判定为失效
+	session, err = newSession(config.LoginConfig{URL: "/login"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.lost(&Result{StatusCode: 401, Location: "/login"}) {
+		t.Error("未配置失效条件时 lost() = true")
+	}
+}
+
+func TestCheckLogin(t *testing.T) {
+	loginURL, _ := url.Parse("http://example.com/login")
+
+	tests := []struct {
+		name     string
+		login    config.LoginConfig
+		status   int
+		header   http.Header
+		body     string
+		jar      string // 登录前罐中的会话 Cookie
+		previous string
+		wantErr  bool
+	}{
+		{name: "默认小于400即成功", status: 302},
+		{name: "默认状态码400以上失败", status: 403, wantErr: true},
+		{name: "指定的成功状态码", login: config.LoginConfig{SuccessStatus: []int{302}}, status: 302},
+		{name: "不是指定的成功状态码", login: config.LoginConfig{SuccessStatus: []int{302}}, status: 200, wantErr: true},
+		{name: "响应体匹配", login: config.LoginConfig{SuccessRegex: `Welcome`}, status: 200, body: "Welcome, admin"},
+		{
+			name:   "响应头匹配",
+			login:  config.LoginConfig{SuccessRegex: `X-User: admin`},
+			status: 200,
+			header: http.Header{"X-User": {"admin"}},
+		},
+		{name: "不匹配", login: config.LoginConfig{SuccessRegex: `Welcome`}, status: 200, body: "invalid password", wantErr: true},
+		{
+			name:   "响应设置了 Cookie",
+			login:  config.LoginConfig{SuccessCookie: "sid"},
+			status: 200,
+			header: http.Header{"Set-Cookie": {"sid=abc; Path=/"}},
+		},
+		{name: "没有 Cookie", login: config.LoginConfig{SuccessCookie: "sid"}, status: 200, wantErr: true},
+		{name: "罐中的 Cookie 已更新", login: config.LoginConfig{SuccessCookie: "sid"}, status: 200, jar: "new", previous: "old"},
+		{name: "罐中仍是失效的 Cookie", login: config.LoginConfig{SuccessCookie: "sid"}, status: 200, jar: "old", previous: "old", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.login.URL = "/login"
+			s := newLoginScanner(t, "http://example.com/", tt.login)
+			if tt.jar != "" {
+				s.client.Jar.SetCookies(loginURL, []*http.Cookie{{Name: "sid", Value: tt.jar, Path: "/"}})
+			}
+			if tt.header == nil {
+				tt.header = http.Header{}
+			}
+
+			resp := &http.Response{StatusCode: tt.status, Header: tt.header}
+			err := s.checkLogin(loginURL, resp, []byte(tt.body), tt.previous)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkLogin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
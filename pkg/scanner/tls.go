@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dirsearch-go/pkg/config"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// tlsVersions 支持的 TLS 版本名称
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsConfig 根据配置创建 TLS 配置：客户端证书、自定义 CA、版本范围、密码套件和 SNI
+func tlsConfig(cfg *config.Config) (*tls.Config, error) {
+	opts := cfg.Scanner.TLS
+	tlsCfg := &tls.Config{
+		InsecureSkipVerify: cfg.Scanner.SkipSSLVerify,
+		ServerName:         opts.ServerName,
+	}
+
+	if opts.CertFile != "" {
+		cert, err := loadClientCert(opts)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	// 指定 CA 后使用它校验服务器证书，不再跳过验证
+	if opts.CAFile != "" {
+		data, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("CA证书中没有有效的 PEM 证书: %s", opts.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+		tlsCfg.InsecureSkipVerify = false
+	}
+
+	var err error
+	if tlsCfg.MinVersion, err = parseTLSVersion(opts.MinVersion); err != nil {
+		return nil, err
+	}
+	if tlsCfg.MaxVersion, err = parseTLSVersion(opts.MaxVersion); err != nil {
+		return nil, err
+	}
+	if tlsCfg.MinVersion != 0 && tlsCfg.MaxVersion != 0 && tlsCfg.MinVersion > tlsCfg.MaxVersion {
+		return nil, fmt.Errorf("TLS 最低版本 %s 高于最高版本 %s", opts.MinVersion, opts.MaxVersion)
+	}
+
+	if len(opts.CipherSuites) > 0 {
+		if tlsCfg.CipherSuites, err = parseCipherSuites(opts.CipherSuites); err != nil {
+			return nil, err
+		}
+	}
+	return tlsCfg, nil
+}
+
+// loadClientCert 加载客户端证书，支持 PEM（证书和私钥可以在同一个文件中）和 PKCS#12 (.p12, .pfx)
+func loadClientCert(opts config.TLSConfig) (tls.Certificate, error) {
+	data, err := os.ReadFile(opts.CertFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(opts.CertFile))
+	if ext == ".p12" || ext == ".pfx" || (opts.KeyFile == "" && !isPEM(data)) {
+		key, cert, chain, err := pkcs12.DecodeChain(data, opts.Password)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("解析 PKCS#12 文件失败: %w", err)
+		}
+		tlsCert := tls.Certificate{PrivateKey: key, Leaf: cert, Certificate: [][]byte{cert.Raw}}
+		for _, c := range chain {
+			tlsCert.Certificate = append(tlsCert.Certificate, c.Raw)
+		}
+		return tlsCert, nil
+	}
+
+	keyData := data
+	if opts.KeyFile != "" {
+		if keyData, err = os.ReadFile(opts.KeyFile); err != nil {
+			return tls.Certificate{}, err
+		}
+	}
+	return tls.X509KeyPair(data, keyData)
+}
+
+// isPEM 判断数据是否为 PEM 格式
+func isPEM(data []byte) bool {
+	block, _ := pem.Decode(data)
+	return block != nil
+}
+
+// parseTLSVersion 解析 TLS 版本，可写成 1.2 或 TLS1.2，为空时返回 0（使用默认值）
+func parseTLSVersion(value string) (uint16, error) {
+	if value == "" {
+		return 0, nil
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(value), "tls"), "v")
+	version, ok := tlsVersions[name]
+	if !ok {
+		return 0, fmt.Errorf("不支持的 TLS 版本: %s (可选 1.0, 1.1, 1.2, 1.3)", value)
+	}
+	return version, nil
+}
+
+// parseCipherSuites 按 IANA 名称解析密码套件（例如 TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256）
+// 只影响 TLS 1.2 及以下版本，TLS 1.3 的密码套件不可配置
+func parseCipherSuites(names []string) ([]uint16, error) {
+	known := make(map[string]uint16)
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("不支持的密码套件: %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+)
+
+// vhostCalibrationKey 虚拟主机扫描的基线缓存键
+const vhostCalibrationKey = "VHOST"
+
+// vhostHost 返回词条对应的 Host，配置了域名时为 WORD.domain
+func (s *Scanner) vhostHost(word string) string {
+	if domain := strings.TrimLeft(s.config.Vhost.Domain, "."); domain != "" {
+		return word + "." + domain
+	}
+	return word
+}
+
+// vhostRequest 创建对目标URL的请求，Host 请求头为 host
+func (s *Scanner) vhostRequest(method, host string) *request {
+	r := s.newRequest(method, s.config.Target)
+	r.host = host
+	return r
+}
+
+// ScanVhost 以词条作为虚拟主机请求目标URL，与不存在的虚拟主机的基线一致的响应会被过滤
+func (s *Scanner) ScanVhost(ctx context.Context, word string) (*Result, error) {
+	// 跳过不能作为主机名的词条
+	word = strings.Trim(strings.TrimSpace(word), ".")
+	if word == "" || strings.ContainsAny(word, "/?#@ \t") {
+		return nil, nil
+	}
+
+	host := s.vhostHost(word)
+	for _, method := range s.config.Scanner.Methods {
+		if result := s.try(ctx, s.vhostRequest(method, host), 0); result != nil {
+			return result, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// CalibrateVhost 请求若干随机的不存在虚拟主机，为每个请求方法建立基线
+// 没有基线的请求方法无法过滤，所有响应都会被报告
+func (s *Scanner) CalibrateVhost(ctx context.Context) ([]*Result, error) {
+	probes, err := s.calibrateMethods(ctx, vhostCalibrationKey, func(method string) (*request, error) {
+		token, err := randomToken()
+		if err != nil {
+			return nil, err
+		}
+		return s.vhostRequest(method, s.vhostHost(token)), nil
+	})
+	if err != nil {
+		return probes, err
+	}
+
+	s.calibrationMu.Lock()
+	defer s.calibrationMu.Unlock()
+	for _, method := range s.config.Scanner.Methods {
+		if s.methodBaseline(vhostCalibrationKey, method) == nil {
+			s.logger.Warn("不存在的虚拟主机响应不稳定，无法建立基线，该方法的所有响应都会被报告", "target", s.config.Target, "method", method)
+		}
+	}
+	return probes, nil
+}
@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"dirsearch-go/pkg/config"
+	"dirsearch-go/pkg/logger"
+)
+
+func TestVhostHost(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   string
+	}{
+		{"", "dev"},
+		{"example.com", "dev.example.com"},
+		{".example.com", "dev.example.com"},
+	}
+
+	for _, tt := range tests {
+		s := &Scanner{config: &config.Config{Vhost: config.VhostConfig{Enabled: true, Domain: tt.domain}}}
+		if got := s.vhostHost("dev"); got != tt.want {
+			t.Errorf("vhostHost(dev) 域名 %q = %s, want %s", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestScanVhost(t *testing.T) {
+	var mu sync.Mutex
+	var hosts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hosts = append(hosts, r.Host)
+		mu.Unlock()
+
+		switch r.Host {
+		case "admin.example.com":
+			fmt.Fprint(w, "<h1>Admin panel</h1><p>Sign in to manage the site.</p>")
+		case "dev.example.com":
+			fmt.Fprint(w, "<h1>Staging</h1>")
+		default:
+			// 不存在的虚拟主机返回回显主机名的默认站点
+			fmt.Fprintf(w, "<h1>Welcome</h1><p>No site configured for %s</p>", r.Host)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Target = srv.URL + "/"
+	cfg.Vhost = config.VhostConfig{Enabled: true, Domain: "example.com"}
+	log, err := logger.New(logger.LevelError, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(cfg, log, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	if _, err := s.CalibrateVhost(ctx); err != nil {
+		t.Fatal(err)
+	}
+	s.calibrationMu.Lock()
+	b := s.methodBaseline(vhostCalibrationKey, "GET")
+	s.calibrationMu.Unlock()
+	if b == nil {
+		t.Fatal("没有建立虚拟主机基线")
+	}
+
+	tests := []struct {
+		word string
+		want string // 报告的 Host，为空表示被过滤
+	}{
+		{"admin", "admin.example.com"},
+		{" dev. ", "dev.example.com"},
+		{"nothere", ""},
+	}
+	for _, tt := range tests {
+		result, err := s.ScanVhost(ctx, tt.word)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case tt.want == "" && result != nil:
+			t.Errorf("ScanVhost(%q) = %+v, want 与基线一致被过滤", tt.word, result)
+		case tt.want != "" && result == nil:
+			t.Errorf("ScanVhost(%q) = nil, want Host %s", tt.word, tt.want)
+		case tt.want != "" && (result.Host != tt.want || result.URL != cfg.Target):
+			t.Errorf("ScanVhost(%q) Host = %s, URL = %s, want %s, %s", tt.word, result.Host, result.URL, tt.want, cfg.Target)
+		}
+	}
+
+	mu.Lock()
+	sent := len(hosts)
+	for _, host := range []string{"admin.example.com", "dev.example.com", "nothere.example.com"} {
+		if !slices.Contains(hosts, host) {
+			t.Errorf("服务器没有收到 Host 为 %s 的请求", host)
+		}
+	}
+	mu.Unlock()
+
+	// 不能作为主机名的词条直接跳过，不发送请求
+	for _, word := range []string{"", " ", "..", "a/b", "a?b", "a#b", "user@host", "a b", "a\tb"} {
+		if result, err := s.ScanVhost(ctx, word); result != nil || err != nil {
+			t.Errorf("ScanVhost(%q) = %v, %v, want 跳过", word, result, err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(hosts) != sent {
+		t.Errorf("无效的词条发送了请求: %q", hosts[sent:])
+	}
+}
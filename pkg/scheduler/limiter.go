@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limits 请求的并发和速率限制，值为 0 表示不限制
+type Limits struct {
+	HostConcurrency int // 同一主机同时进行的请求数
+	HostRate        int // 同一主机每秒发出的请求数
+	Rate            int // 所有主机每秒发出的请求数
+}
+
+// Limiter 按主机和全局限制请求的并发数和速率
+// 扫描器发出的每个请求（包括校准、预扫描、登录、重试和重放）都需要先取得名额，线程池只负责分配任务
+type Limiter struct {
+	mu     sync.Mutex
+	cond   *sync.Cond // 有名额释放或上下文取消时通知等待的请求
+	limits Limits
+	hosts  map[string]*hostState // 按主机统计的并发数和限速状态
+	next   time.Time             // 全局限速下允许发出下一个请求的时间
+	notify func()                // 名额释放时通知线程池，为 nil 时不通知
+}
+
+// hostState 单个主机的并发数和限速状态
+type hostState struct {
+	active int       // 正在进行的请求数
+	next   time.Time // 允许发出下一个请求的时间
+}
+
+// NewLimiter 创建限制器
+func NewLimiter(limits Limits) *Limiter {
+	l := &Limiter{
+		limits: limits,
+		hosts:  make(map[string]*hostState),
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// Acquire 等待主机的并发名额和限速时间后返回，请求完成（响应体关闭）后调用 release 归还名额
+// 上下文取消时放弃等待并返回错误。l 为 nil 时不做限制
+func (l *Limiter) Acquire(ctx context.Context, host string) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.cond.Broadcast()
+	})
+	defer stop()
+
+	l.mu.Lock()
+	h := l.host(host)
+	for l.limits.HostConcurrency > 0 && h.active >= l.limits.HostConcurrency {
+		if err := ctx.Err(); err != nil {
+			l.mu.Unlock()
+			return nil, err
+		}
+		l.cond.Wait()
+	}
+	h.active++
+
+	// 预约发出时间，全局和主机的下一个名额都从这个时间开始计算
+	at := later(time.Now(), later(l.next, h.next))
+	if l.limits.Rate > 0 {
+		l.next = at.Add(time.Second / time.Duration(l.limits.Rate))
+	}
+	if l.limits.HostRate > 0 {
+		h.next = at.Add(time.Second / time.Duration(l.limits.HostRate))
+	}
+	l.mu.Unlock()
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			l.mu.Lock()
+			h.active--
+			l.cond.Broadcast()
+			l.mu.Unlock()
+			if l.notify != nil {
+				l.notify()
+			}
+		})
+	}
+
+	if wait := time.Until(at); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// ready 判断主机当前能否立即发出请求，不占用名额；受限速影响时同时返回可以发出的时间
+func (l *Limiter) ready(host string) (bool, time.Time) {
+	if l == nil {
+		return true, time.Time{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h := l.host(host)
+	if l.limits.HostConcurrency > 0 && h.active >= l.limits.HostConcurrency {
+		return false, time.Time{}
+	}
+	if at := later(l.next, h.next); at.After(time.Now()) {
+		return false, at
+	}
+	return true, time.Time{}
+}
+
+// host 返回主机的状态，不存在时创建，调用方需持有锁
+func (l *Limiter) host(host string) *hostState {
+	h := l.hosts[host]
+	if h == nil {
+		h = &hostState{}
+		l.hosts[host] = h
+	}
+	return h
+}
+
+// later 返回两个时间中较晚的一个
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterConcurrency(t *testing.T) {
+	l := NewLimiter(Limits{HostConcurrency: 1})
+
+	release, err := l.Acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 同一主机没有名额，其他主机不受影响
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, "a"); err == nil {
+		t.Fatal("主机没有并发名额时 Acquire() 没有等待")
+	}
+	releaseB, err := l.Acquire(context.Background(), "b")
+	if err != nil {
+		t.Fatalf("其他主机 Acquire() error = %v", err)
+	}
+	releaseB()
+
+	// 归还名额后等待的请求继续执行，重复归还不会多出名额
+	acquired := make(chan func())
+	go func() {
+		r, err := l.Acquire(context.Background(), "a")
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- r
+	}()
+	release()
+	release()
+	select {
+	case r := <-acquired:
+		if ready, _ := l.ready("a"); ready {
+			t.Error("重复归还后主机仍有空闲名额")
+		}
+		r()
+	case <-time.After(time.Second):
+		t.Fatal("归还名额后等待的请求没有继续")
+	}
+}
+
+func TestLimiterRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		hosts  []string
+		min    time.Duration
+	}{
+		{"主机限速", Limits{HostRate: 20}, []string{"a", "a", "a"}, 100 * time.Millisecond},
+		{"主机限速不影响其他主机", Limits{HostRate: 5}, []string{"a", "b", "c"}, 0},
+		{"全局限速", Limits{Rate: 20}, []string{"a", "b", "c"}, 100 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(tt.limits)
+			start := time.Now()
+			for _, host := range tt.hosts {
+				release, err := l.Acquire(context.Background(), host)
+				if err != nil {
+					t.Fatal(err)
+				}
+				release()
+			}
+			elapsed := time.Since(start)
+			if elapsed < tt.min || elapsed > tt.min+150*time.Millisecond {
+				t.Errorf("耗时 %v, want 约 %v", elapsed, tt.min)
+			}
+		})
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	l := NewLimiter(Limits{HostConcurrency: 1, HostRate: 1})
+
+	release, err := l.Acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	// 等待限速时取消，名额应当归还
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := l.Acquire(ctx, "a"); err != context.Canceled {
+		t.Fatalf("Acquire() error = %v, want %v", err, context.Canceled)
+	}
+	l.mu.Lock()
+	active := l.hosts["a"].active
+	l.mu.Unlock()
+	if active != 0 {
+		t.Errorf("取消后主机的并发数 = %d, want 0", active)
+	}
+}
+
+func TestNilLimiter(t *testing.T) {
+	var l *Limiter
+	release, err := l.Acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if ready, _ := l.ready("a"); !ready {
+		t.Error("未限制时 ready() = false")
+	}
+}
@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Pool 在多个目标的调度器之间分配工作线程
+// 各目标轮流出队；请求的并发数和速率由 Limiter 在发送时限制，线程池只参考其状态，
+// 暂时没有名额的主机的任务让给其他主机，目标完成后空出的线程自动分配给仍有任务的目标
+type Pool struct {
+	mu      sync.Mutex
+	cond    *sync.Cond // 有新任务、任务完成、名额释放或限速到期时通知工作线程
+	limiter *Limiter
+	queues  []*queue
+	cursor  int         // 下一次从哪个目标开始轮询
+	timer   *time.Timer // 限速到期时唤醒工作线程
+	timeAt  time.Time   // timer 的到期时间
+	closed  bool        // 是否不再添加新的目标
+}
+
+// queue 加入线程池的目标
+type queue struct {
+	host      string
+	scheduler *Scheduler
+	handle    func(Job)
+	done      chan struct{} // 目标的所有任务完成后关闭
+}
+
+// NewPool 创建线程池，limiter 为扫描器发送请求时使用的限制器，为 nil 时不限制
+func NewPool(limiter *Limiter) *Pool {
+	p := &Pool{limiter: limiter}
+	p.cond = sync.NewCond(&p.mu)
+	if limiter != nil {
+		limiter.notify = p.wake
+	}
+	return p
+}
+
+// Add 将目标的调度器加入线程池，任务由 handle 在工作线程中执行
+// 返回的通道在调度器关闭且所有任务完成后关闭
+func (p *Pool) Add(host string, s *Scheduler, handle func(Job)) <-chan struct{} {
+	q := &queue{host: host, scheduler: s, handle: handle, done: make(chan struct{})}
+
+	s.mu.Lock()
+	s.notify = p.wake
+	s.mu.Unlock()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queues = append(p.queues, q)
+	p.cond.Broadcast()
+	return q.done
+}
+
+// Close 表示不再添加新的目标，所有目标完成后 Run 返回
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	p.cond.Broadcast()
+}
+
+// Run 启动 workers 个工作线程执行任务，直到线程池关闭且所有目标完成，或上下文被取消
+func (p *Pool) Run(ctx context.Context, workers int) {
+	stop := context.AfterFunc(ctx, p.wake)
+	defer stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				q, job, ok := p.take(ctx)
+				if !ok {
+					return
+				}
+				q.handle(job)
+				q.scheduler.Done()
+				p.wake()
+			}
+		}()
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+}
+
+// take 轮流从各目标取出下一个可以执行的任务，没有可执行的任务时阻塞等待
+func (p *Pool) take(ctx context.Context) (*queue, Job, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		if ctx.Err() != nil {
+			return nil, Job{}, false
+		}
+
+		var retry time.Time // 受限速影响的目标最早可以出队的时间
+		for i := 0; i < len(p.queues); i++ {
+			index := (p.cursor + i) % len(p.queues)
+			q := p.queues[index]
+
+			if ready, at := p.limiter.ready(q.host); !ready {
+				if !at.IsZero() && (retry.IsZero() || at.Before(retry)) {
+					retry = at
+				}
+				continue
+			}
+
+			job, ok, finished := q.scheduler.tryPop()
+			if finished {
+				p.remove(index)
+				i--
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			p.cursor = index + 1
+			return q, job, true
+		}
+
+		if p.closed && len(p.queues) == 0 {
+			return nil, Job{}, false
+		}
+		if !retry.IsZero() {
+			p.wakeAt(retry)
+		}
+		p.cond.Wait()
+	}
+}
+
+// remove 移除已完成的目标，调用方需持有锁
+func (p *Pool) remove(index int) {
+	close(p.queues[index].done)
+	p.queues = append(p.queues[:index], p.queues[index+1:]...)
+	if p.cursor > index {
+		p.cursor--
+	}
+	if len(p.queues) == 0 {
+		p.cursor = 0
+		p.cond.Broadcast()
+	}
+}
+
+// wake 唤醒所有等待任务的工作线程
+func (p *Pool) wake() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cond.Broadcast()
+}
+
+// wakeAt 在限速到期时唤醒工作线程，调用方需持有锁
+func (p *Pool) wakeAt(at time.Time) {
+	if !p.timeAt.IsZero() && !at.Before(p.timeAt) {
+		return
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.timeAt = at
+	p.timer = time.AfterFunc(time.Until(at), func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.timeAt = time.Time{}
+		p.cond.Broadcast()
+	})
+}
@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPoolHostLimit 主机没有并发名额时，它的任务让给其他主机，线程不会阻塞在受限的主机上
+func TestPoolHostLimit(t *testing.T) {
+	limiter := NewLimiter(Limits{HostConcurrency: 1})
+	pool := NewPool(limiter)
+
+	var mu sync.Mutex
+	var order []string
+	handle := func(host string) func(Job) {
+		return func(j Job) {
+			release, err := limiter.Acquire(context.Background(), host)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+			mu.Lock()
+			order = append(order, j.URL())
+			mu.Unlock()
+		}
+	}
+
+	// 主机 a 的名额被其他请求（例如校准）占用，直到主机 b 的任务全部完成
+	hold, err := limiter.Acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slow := New(BFS)
+	slow.Push(Job{Base: "http://a", Word: "1"}, Job{Base: "http://a", Word: "2"})
+	slow.Close()
+	fast := New(BFS)
+	fast.Push(Job{Base: "http://b", Word: "1"}, Job{Base: "http://b", Word: "2"})
+	fast.Close()
+	slowDone := pool.Add("a", slow, handle("a"))
+	fastDone := pool.Add("b", fast, handle("b"))
+	pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	finished := make(chan struct{})
+	go func() {
+		pool.Run(ctx, 2)
+		close(finished)
+	}()
+
+	select {
+	case <-fastDone:
+	case <-time.After(time.Second):
+		t.Fatal("受限主机占用了线程，其他主机的任务没有执行")
+	}
+	hold()
+	select {
+	case <-slowDone:
+	case <-time.After(time.Second):
+		t.Fatal("归还名额后受限主机的任务没有执行")
+	}
+	<-finished
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 4 || order[0] != "http://b/1" || order[1] != "http://b/2" {
+		t.Errorf("执行顺序 %v, want 先执行主机 b 的任务", order)
+	}
+}
+
+// TestPoolRate 受限速影响的主机到期后继续出队
+func TestPoolRate(t *testing.T) {
+	limiter := NewLimiter(Limits{Rate: 20})
+	pool := NewPool(limiter)
+
+	s := New(BFS)
+	for _, word := range []string{"1", "2", "3", "4"} {
+		s.Push(Job{Base: "http://a", Word: word})
+	}
+	s.Close()
+	pool.Add("a", s, func(Job) {
+		release, err := limiter.Acquire(context.Background(), "a")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		release()
+	})
+	pool.Close()
+
+	start := time.Now()
+	pool.Run(context.Background(), 4)
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("4 个请求耗时 %v, want 至少 150ms", elapsed)
+	}
+}
@@ -0,0 +1,188 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Order 任务出队顺序
+type Order string
+
+const (
+	BFS Order = "bfs" // 广度优先：先完成浅层任务
+	DFS Order = "dfs" // 深度优先：优先执行更深的递归任务
+)
+
+// Job 扫描任务
+type Job struct {
+	Base        string            // 任务所在目录的URL
+	Word        string            // 词典条目或相对路径
+	Depth       int               // 递归深度
+	Source      string            // 任务来源，会记录到扫描结果中
+	Payload     map[string]string // 非空时将各关键字的词条代入请求模板，而不是把 Word 追加到 Base 后面
+	DerivedFrom string            // 衍生出该任务的命中URL，会记录到扫描结果中
+}
+
+// URL 返回任务对应的完整URL
+func (j Job) URL() string {
+	return strings.TrimRight(j.Base, "/") + "/" + strings.TrimLeft(j.Word, "/")
+}
+
+// key 返回用于去重的键，返回空字符串表示不需要去重
+// 模板任务的词条组合在生成时就不会重复，记录它们只会占用内存
+func (j Job) key() string {
+	if j.Payload != nil {
+		return ""
+	}
+	return j.URL()
+}
+
+// Scheduler 单个目标的任务调度器，由线程池 (Pool) 从中取任务
+// 任务按深度分层排队，同一层内先进先出；已请求过的URL（或 FUZZ 词条）不会再次入队
+type Scheduler struct {
+	mu      sync.Mutex
+	space   *sync.Cond // 有任务出队时通知等待提交的一方
+	notify  func()     // 有新任务或全部完成时通知线程池，调用时不持有锁
+	order   Order
+	levels  [][]Job             // 按深度分层的待执行任务
+	visited map[string]struct{} // 已入队过的URL
+	queued  int                 // 排队中尚未取出的任务数
+	pending int                 // 已入队但尚未完成的任务数（包括正在执行的任务）
+	closed  bool                // 是否不再接收外部任务
+}
+
+// New 创建任务调度器
+func New(order Order) *Scheduler {
+	s := &Scheduler{
+		order:   order,
+		visited: make(map[string]struct{}),
+	}
+	s.space = sync.NewCond(&s.mu)
+	return s
+}
+
+// Push 将任务加入队列，返回实际入队的任务数（重复的URL会被忽略）
+func (s *Scheduler) Push(jobs ...Job) int {
+	s.mu.Lock()
+	added := 0
+	for _, job := range jobs {
+		if key := job.key(); key != "" {
+			if _, ok := s.visited[key]; ok {
+				continue
+			}
+			s.visited[key] = struct{}{}
+		}
+
+		for len(s.levels) <= job.Depth {
+			s.levels = append(s.levels, nil)
+		}
+		s.levels[job.Depth] = append(s.levels[job.Depth], job)
+		added++
+	}
+
+	s.queued += added
+	s.pending += added
+	s.mu.Unlock()
+
+	if added > 0 {
+		s.wake()
+	}
+	return added
+}
+
+// wake 通知线程池调度器的状态发生了变化
+func (s *Scheduler) wake() {
+	s.mu.Lock()
+	notify := s.notify
+	s.mu.Unlock()
+	if notify != nil {
+		notify()
+	}
+}
+
+// Wait 阻塞直到排队中的任务少于 limit，上下文被取消时返回 false
+// 用于分批提交数量很大的初始任务，避免一次性全部放入内存
+func (s *Scheduler) Wait(ctx context.Context, limit int) bool {
+	stop := context.AfterFunc(ctx, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.space.Broadcast()
+	})
+	defer stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.queued >= limit {
+		if ctx.Err() != nil {
+			return false
+		}
+		s.space.Wait()
+	}
+	return ctx.Err() == nil
+}
+
+// Close 表示初始任务已全部提交，此后只有正在执行的任务还能产生新任务
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.wake()
+}
+
+// tryPop 不阻塞地取出下一个任务，finished 表示所有任务都已完成且不会再有新任务
+func (s *Scheduler) tryPop() (job Job, ok bool, finished bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.next(); ok {
+		return job, true, false
+	}
+	return Job{}, false, s.closed && s.pending == 0
+}
+
+// next 按出队顺序取出一个任务，调用方需持有锁
+func (s *Scheduler) next() (Job, bool) {
+	if s.order == DFS {
+		for depth := len(s.levels) - 1; depth >= 0; depth-- {
+			if job, ok := s.shift(depth); ok {
+				return job, true
+			}
+		}
+		return Job{}, false
+	}
+
+	for depth := range s.levels {
+		if job, ok := s.shift(depth); ok {
+			return job, true
+		}
+	}
+	return Job{}, false
+}
+
+// shift 取出指定深度队列的第一个任务
+func (s *Scheduler) shift(depth int) (Job, bool) {
+	queue := s.levels[depth]
+	if len(queue) == 0 {
+		return Job{}, false
+	}
+	job := queue[0]
+	queue[0] = Job{}
+	s.levels[depth] = queue[1:]
+	s.queued--
+	s.space.Signal()
+	return job, true
+}
+
+// Done 标记一个任务执行完毕，必须在该任务产生的新任务入队之后调用
+func (s *Scheduler) Done() {
+	s.mu.Lock()
+	s.pending--
+	drained := s.pending == 0
+	s.mu.Unlock()
+
+	if drained {
+		s.wake()
+	}
+}
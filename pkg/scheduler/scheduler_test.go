@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// drain 按出队顺序取出所有任务的URL
+func drain(s *Scheduler) []string {
+	var urls []string
+	for {
+		job, ok, _ := s.tryPop()
+		if !ok {
+			return urls
+		}
+		urls = append(urls, job.URL())
+	}
+}
+
+func TestSchedulerOrder(t *testing.T) {
+	jobs := []Job{
+		{Base: "http://a/", Word: "x", Depth: 0},
+		{Base: "http://a/d1/", Word: "x", Depth: 1},
+		{Base: "http://a/", Word: "y", Depth: 0},
+		{Base: "http://a/d1/d2/", Word: "x", Depth: 2},
+		{Base: "http://a/d1/", Word: "y", Depth: 1},
+	}
+
+	tests := []struct {
+		order Order
+		want  []string
+	}{
+		{BFS, []string{"http://a/x", "http://a/y", "http://a/d1/x", "http://a/d1/y", "http://a/d1/d2/x"}},
+		{DFS, []string{"http://a/d1/d2/x", "http://a/d1/x", "http://a/d1/y", "http://a/x", "http://a/y"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			s := New(tt.order)
+			s.Push(jobs...)
+			got := drain(s)
+			if len(got) != len(tt.want) {
+				t.Fatalf("出队 %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("出队 %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSchedulerDedup(t *testing.T) {
+	tests := []struct {
+		name string
+		jobs []Job
+		want int
+	}{
+		{"相同URL", []Job{{Base: "http://a", Word: "x"}, {Base: "http://a/", Word: "/x"}}, 1},
+		{"不同深度的相同URL", []Job{{Base: "http://a", Word: "x"}, {Base: "http://a", Word: "x", Depth: 2}}, 1},
+		{"不同URL", []Job{{Base: "http://a", Word: "x"}, {Base: "http://a", Word: "y"}}, 2},
+		{"模板任务不去重", []Job{{Payload: map[string]string{"FUZZ": "x"}}, {Payload: map[string]string{"FUZZ": "x"}}}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(BFS)
+			if got := s.Push(tt.jobs...); got != tt.want {
+				t.Errorf("Push() = %d, want %d", got, tt.want)
+			}
+			// 已出队的URL也不能再次入队
+			drain(s)
+			if got := s.Push(tt.jobs[0]); tt.jobs[0].Payload == nil && got != 0 {
+				t.Errorf("再次 Push() = %d, want 0", got)
+			}
+		})
+	}
+}
+
+func TestSchedulerFinished(t *testing.T) {
+	s := New(BFS)
+	s.Push(Job{Base: "http://a", Word: "x"})
+	s.Close()
+
+	if _, ok, finished := s.tryPop(); !ok || finished {
+		t.Fatalf("tryPop() ok=%v finished=%v, want 取出任务且未完成", ok, finished)
+	}
+	// 任务执行中仍可能产生新任务，不能视为完成
+	if _, ok, finished := s.tryPop(); ok || finished {
+		t.Fatalf("tryPop() ok=%v finished=%v, want 没有任务且未完成", ok, finished)
+	}
+	s.Done()
+	if _, _, finished := s.tryPop(); !finished {
+		t.Fatal("所有任务完成后 finished = false")
+	}
+}
+
+func TestSchedulerWait(t *testing.T) {
+	s := New(BFS)
+	s.Push(Job{Base: "http://a", Word: "x"}, Job{Base: "http://a", Word: "y"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if s.Wait(ctx, 2) {
+		t.Fatal("排队任务达到上限时 Wait() = true")
+	}
+
+	s.tryPop()
+	if !s.Wait(context.Background(), 2) {
+		t.Fatal("排队任务低于上限时 Wait() = false")
+	}
+}
@@ -0,0 +1,89 @@
+package useragent
+
+import (
+	"math/rand/v2"
+	"strings"
+)
+
+// 各浏览器导航请求默认发送的 Accept 和 Accept-Language
+const (
+	chromeAccept  = "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"
+	firefoxAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+	safariAccept  = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+)
+
+// Profile 一个浏览器的请求特征
+type Profile struct {
+	UserAgent      string
+	Accept         string // 为空时不设置
+	AcceptLanguage string // 为空时不设置
+}
+
+// builtin 内置的常见浏览器
+var builtin = []Profile{
+	// Chrome
+	{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36", chromeAccept, "en-US,en;q=0.9"},
+	{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0.0.0 Safari/537.36", chromeAccept, "zh-CN,zh;q=0.9,en;q=0.8"},
+	{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36", chromeAccept, "en-US,en;q=0.9"},
+	{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36", chromeAccept, "en-US,en;q=0.9"},
+	{"Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36", chromeAccept, "en-US,en;q=0.9"},
+	// Edge
+	{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36 Edg/131.0.0.0", chromeAccept, "en-US,en;q=0.9"},
+	// Firefox
+	{"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:133.0) Gecko/20100101 Firefox/133.0", firefoxAccept, "en-US,en;q=0.5"},
+	{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:133.0) Gecko/20100101 Firefox/133.0", firefoxAccept, "en-US,en;q=0.5"},
+	{"Mozilla/5.0 (X11; Linux x86_64; rv:133.0) Gecko/20100101 Firefox/133.0", firefoxAccept, "en-US,en;q=0.5"},
+	{"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:132.0) Gecko/20100101 Firefox/132.0", firefoxAccept, "de-DE,de;q=0.8,en-US;q=0.5,en;q=0.3"},
+	// Safari
+	{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.1 Safari/605.1.15", safariAccept, "en-US,en;q=0.9"},
+	{"Mozilla/5.0 (iPhone; CPU iPhone OS 18_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.1 Mobile/15E148 Safari/604.1", safariAccept, "en-US,en;q=0.9"},
+	{"Mozilla/5.0 (iPad; CPU OS 17_7 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.6 Mobile/15E148 Safari/604.1", safariAccept, "ja-JP,ja;q=0.9"},
+}
+
+// Builtin 返回内置的浏览器列表
+func Builtin() []Profile {
+	return append([]Profile(nil), builtin...)
+}
+
+// ForAgent 根据用户代理字符串判断浏览器，返回带有对应请求头的 Profile
+func ForAgent(userAgent string) Profile {
+	profile := Profile{UserAgent: userAgent, AcceptLanguage: "en-US,en;q=0.9"}
+	switch {
+	case strings.Contains(userAgent, "Firefox/"):
+		profile.Accept, profile.AcceptLanguage = firefoxAccept, "en-US,en;q=0.5"
+	case strings.Contains(userAgent, "Chrome/"), strings.Contains(userAgent, "Chromium/"):
+		profile.Accept = chromeAccept
+	case strings.Contains(userAgent, "Safari/"):
+		profile.Accept = safariAccept
+	default:
+		// 无法识别的客户端（例如爬虫、命令行工具）不伪造浏览器的请求头
+		profile.Accept, profile.AcceptLanguage = "", ""
+	}
+	return profile
+}
+
+// Picker 从列表中随机选择浏览器
+type Picker struct {
+	profiles []Profile
+}
+
+// New 创建 Picker，headers 为 false 时只使用用户代理，不发送 Accept 和 Accept-Language
+func New(profiles []Profile, headers bool) *Picker {
+	p := &Picker{profiles: append([]Profile(nil), profiles...)}
+	if !headers {
+		for i := range p.profiles {
+			p.profiles[i].Accept, p.profiles[i].AcceptLanguage = "", ""
+		}
+	}
+	return p
+}
+
+// Next 随机返回一个浏览器
+func (p *Picker) Next() Profile {
+	return p.profiles[rand.IntN(len(p.profiles))]
+}
+
+// Len 返回列表中的浏览器数量
+func (p *Picker) Len() int {
+	return len(p.profiles)
+}
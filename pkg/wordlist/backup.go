@@ -0,0 +1,26 @@
+package wordlist
+
+import (
+	"path"
+	"strings"
+)
+
+// BackupVariants 根据命中的文件名生成备份文件和编辑器临时文件的名称
+// 模板中的 {file} 为完整文件名（config.php），{name} 为去掉扩展名的部分（config），{ext} 为不带点号的扩展名（php）
+func BackupVariants(file string, patterns []string) []string {
+	ext := path.Ext(file)
+	name := strings.TrimSuffix(file, ext)
+	replacer := strings.NewReplacer("{file}", file, "{name}", name, "{ext}", strings.TrimPrefix(ext, "."))
+
+	seen := map[string]bool{file: true}
+	var variants []string
+	for _, pattern := range patterns {
+		variant := replacer.Replace(pattern)
+		if variant == "" || strings.Contains(variant, "/") || seen[variant] {
+			continue
+		}
+		seen[variant] = true
+		variants = append(variants, variant)
+	}
+	return variants
+}
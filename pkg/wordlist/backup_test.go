@@ -0,0 +1,35 @@
+package wordlist
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBackupVariants(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		patterns []string
+		want     []string
+	}{
+		{
+			"文件名、主名和扩展名",
+			"config.php",
+			[]string{"{file}.bak", "{file}~", "{name}.old", ".{file}.swp", "{name}_{ext}.txt"},
+			[]string{"config.php.bak", "config.php~", "config.old", ".config.php.swp", "config_php.txt"},
+		},
+		{"没有扩展名", "README", []string{"{file}.bak", "{name}.{ext}"}, []string{"README.bak", "README."}},
+		{"多个点号只取最后的扩展名", "app.tar.gz", []string{"{name}.zip"}, []string{"app.tar.zip"}},
+		{"跳过与原文件相同的名称", "index.html", []string{"{file}", "{name}.{ext}", "{file}.orig"}, []string{"index.html.orig"}},
+		{"去重", "a.js", []string{"{file}.bak", "{name}.js.bak"}, []string{"a.js.bak"}},
+		{"跳过空名称和包含斜杠的名称", "a.js", []string{"", "backup/{file}"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BackupVariants(tt.file, tt.patterns); !slices.Equal(got, tt.want) {
+				t.Errorf("BackupVariants(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package wordlist
+
+import "iter"
+
+// Mode 多个词典的组合方式
+type Mode string
+
+const (
+	ClusterBomb Mode = "clusterbomb" // 笛卡尔积：尝试所有词条组合
+	Pitchfork   Mode = "pitchfork"   // 按行对齐：第 N 个请求使用每个词典的第 N 行，以最短的词典为准
+)
+
+// List 绑定到请求模板关键字的词典
+type List struct {
+	Keyword string   // 模板中被替换的关键字，例如 FUZZ、USER
+	Words   []string // 词条
+}
+
+// Count 返回按指定方式组合后的请求数
+func Count(mode Mode, lists []List) int {
+	if len(lists) == 0 {
+		return 0
+	}
+
+	if mode == Pitchfork {
+		count := len(lists[0].Words)
+		for _, list := range lists[1:] {
+			count = min(count, len(list.Words))
+		}
+		return count
+	}
+
+	count := 1
+	for _, list := range lists {
+		count *= len(list.Words)
+	}
+	return count
+}
+
+// Combinations 按指定方式依次生成每个请求的关键字取值
+// 组合是惰性生成的，笛卡尔积很大时也不会一次性占用内存。每次产生的 map 都是新的，调用方可以直接保存
+func Combinations(mode Mode, lists []List) iter.Seq[map[string]string] {
+	return func(yield func(map[string]string) bool) {
+		if Count(mode, lists) == 0 {
+			return
+		}
+
+		if mode == Pitchfork {
+			for i := range Count(mode, lists) {
+				payload := make(map[string]string, len(lists))
+				for _, list := range lists {
+					payload[list.Keyword] = list.Words[i]
+				}
+				if !yield(payload) {
+					return
+				}
+			}
+			return
+		}
+
+		// 笛卡尔积：indexes 作为多进制计数器，最后一个词典变化最快
+		indexes := make([]int, len(lists))
+		for {
+			payload := make(map[string]string, len(lists))
+			for i, list := range lists {
+				payload[list.Keyword] = list.Words[indexes[i]]
+			}
+			if !yield(payload) {
+				return
+			}
+
+			i := len(lists) - 1
+			for ; i >= 0; i-- {
+				indexes[i]++
+				if indexes[i] < len(lists[i].Words) {
+					break
+				}
+				indexes[i] = 0
+			}
+			if i < 0 {
+				return
+			}
+		}
+	}
+}
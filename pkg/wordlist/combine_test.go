@@ -0,0 +1,90 @@
+package wordlist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  Mode
+		lists []List
+		want  int
+	}{
+		{"没有词典", ClusterBomb, nil, 0},
+		{"笛卡尔积", ClusterBomb, []List{{"A", []string{"1", "2"}}, {"B", []string{"x", "y", "z"}}}, 6},
+		{"笛卡尔积含空词典", ClusterBomb, []List{{"A", []string{"1", "2"}}, {"B", nil}}, 0},
+		{"按行对齐取最短", Pitchfork, []List{{"A", []string{"1", "2"}}, {"B", []string{"x", "y", "z"}}}, 2},
+		{"单个词典", Pitchfork, []List{{"A", []string{"1", "2", "3"}}}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Count(tt.mode, tt.lists); got != tt.want {
+				t.Errorf("Count() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	lists := []List{{"A", []string{"1", "2"}}, {"B", []string{"x", "y", "z"}}}
+
+	tests := []struct {
+		name  string
+		mode  Mode
+		lists []List
+		want  []map[string]string
+	}{
+		{
+			name:  "笛卡尔积最后一个词典变化最快",
+			mode:  ClusterBomb,
+			lists: lists,
+			want: []map[string]string{
+				{"A": "1", "B": "x"}, {"A": "1", "B": "y"}, {"A": "1", "B": "z"},
+				{"A": "2", "B": "x"}, {"A": "2", "B": "y"}, {"A": "2", "B": "z"},
+			},
+		},
+		{
+			name:  "按行对齐",
+			mode:  Pitchfork,
+			lists: lists,
+			want:  []map[string]string{{"A": "1", "B": "x"}, {"A": "2", "B": "y"}},
+		},
+		{
+			name:  "空词典不产生组合",
+			mode:  ClusterBomb,
+			lists: []List{{"A", []string{"1"}}, {"B", nil}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []map[string]string
+			for payload := range Combinations(tt.mode, tt.lists) {
+				got = append(got, payload)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Combinations() = %v, want %v", got, tt.want)
+			}
+			if len(got) != Count(tt.mode, tt.lists) {
+				t.Errorf("生成 %d 个组合，Count() = %d", len(got), Count(tt.mode, tt.lists))
+			}
+		})
+	}
+}
+
+func TestCombinationsStop(t *testing.T) {
+	lists := []List{{"A", []string{"1", "2", "3"}}, {"B", []string{"x", "y"}}}
+	n := 0
+	for range Combinations(ClusterBomb, lists) {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("提前结束后生成了 %d 个组合, want 2", n)
+	}
+}
@@ -0,0 +1,156 @@
+package wordlist
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ExtensionTag 词条中被替换为各个扩展名的占位符
+const ExtensionTag = "%EXT%"
+
+// Options 词典变换选项
+type Options struct {
+	Extensions        []string // 替换 %EXT% 或强制追加的扩展名
+	ForceExtensions   bool     // 为每个词条追加所有扩展名，而不只是替换 %EXT%
+	ExcludeExtensions []string // 丢弃以这些扩展名结尾的词条
+	RemoveExtensions  bool     // 去掉词条的扩展名，例如 admin.php -> admin
+	Prefixes          []string // 额外生成加上前缀的词条，例如 . _ ~
+	Suffixes          []string // 额外生成加上后缀的词条，例如 ~ .bak /
+	Uppercase         bool     // 转为大写
+	Lowercase         bool     // 转为小写
+	Capitalize        bool     // 首字母大写，其余小写
+}
+
+// Load 读取词典文件并按选项生成词条
+func Load(path string, opts Options) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开词典文件失败: %w", err)
+	}
+	defer file.Close()
+
+	var words []string
+	fileScanner := bufio.NewScanner(file)
+	for fileScanner.Scan() {
+		words = append(words, opts.Generate(fileScanner.Text())...)
+	}
+	return words, fileScanner.Err()
+}
+
+// Generate 对单行词条依次应用扩展名展开、扩展名过滤、大小写转换和前后缀，返回生成的所有词条
+func (o Options) Generate(line string) []string {
+	var words []string
+	for _, word := range o.expand(line) {
+		if o.excluded(word) {
+			continue
+		}
+		if o.RemoveExtensions {
+			word = removeExtension(word)
+		}
+
+		for _, cased := range o.cases(word) {
+			words = append(words, cased)
+			for _, prefix := range o.Prefixes {
+				if !strings.HasPrefix(cased, prefix) {
+					words = append(words, prefix+cased)
+				}
+			}
+			for _, suffix := range o.Suffixes {
+				if !strings.HasSuffix(cased, "/") && !strings.HasSuffix(cased, suffix) {
+					words = append(words, cased+suffix)
+				}
+			}
+		}
+	}
+	return words
+}
+
+// expand 展开 %EXT% 占位符；开启强制扩展名时，为不以 / 结尾的词条追加每个扩展名（已是该扩展名的除外）
+// 没有配置扩展名时，包含占位符的词条会被丢弃
+func (o Options) expand(line string) []string {
+	if strings.Contains(line, ExtensionTag) {
+		words := make([]string, 0, len(o.Extensions))
+		for _, ext := range o.Extensions {
+			ext = strings.TrimPrefix(ext, ".")
+			// 占位符前已经有点号时直接使用扩展名，避免出现双点号
+			if strings.Contains(line, "."+ExtensionTag) {
+				words = append(words, strings.ReplaceAll(line, ExtensionTag, ext))
+			} else {
+				words = append(words, strings.ReplaceAll(line, ExtensionTag, "."+ext))
+			}
+		}
+		return words
+	}
+
+	words := []string{line}
+	if o.ForceExtensions && line != "" && !strings.HasSuffix(line, "/") {
+		for _, ext := range o.Extensions {
+			ext = "." + strings.TrimPrefix(ext, ".")
+			if !strings.HasSuffix(strings.ToLower(line), strings.ToLower(ext)) {
+				words = append(words, line+ext)
+			}
+		}
+	}
+	return words
+}
+
+// excluded 判断词条是否以排除的扩展名结尾
+func (o Options) excluded(word string) bool {
+	lower := strings.ToLower(word)
+	for _, ext := range o.ExcludeExtensions {
+		if strings.HasSuffix(lower, "."+strings.ToLower(strings.TrimPrefix(ext, "."))) {
+			return true
+		}
+	}
+	return false
+}
+
+// cases 返回大小写转换后的词条，同时开启多个选项时每种形式各生成一个
+func (o Options) cases(word string) []string {
+	if !o.Uppercase && !o.Lowercase && !o.Capitalize {
+		return []string{word}
+	}
+
+	var words []string
+	add := func(cased string) {
+		for _, existing := range words {
+			if existing == cased {
+				return
+			}
+		}
+		words = append(words, cased)
+	}
+	if o.Uppercase {
+		add(strings.ToUpper(word))
+	}
+	if o.Lowercase {
+		add(strings.ToLower(word))
+	}
+	if o.Capitalize {
+		add(capitalize(word))
+	}
+	return words
+}
+
+// capitalize 首字母大写，其余小写
+func capitalize(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	if r == utf8.RuneError {
+		return word
+	}
+	return string(unicode.ToUpper(r)) + strings.ToLower(word[size:])
+}
+
+// removeExtension 去掉路径最后一段中第一个点号之后的部分，以点号开头的文件名（例如 .htaccess）保持不变
+func removeExtension(word string) string {
+	start := strings.LastIndex(word, "/") + 1
+	name := word[start:]
+	if i := strings.Index(name, "."); i > 0 {
+		return word[:start+i]
+	}
+	return word
+}
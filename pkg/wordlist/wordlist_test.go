@@ -0,0 +1,79 @@
+package wordlist
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		line string
+		want []string
+	}{
+		{"无选项", Options{}, "admin", []string{"admin"}},
+		{"替换占位符", Options{Extensions: []string{"php", ".asp"}}, "index.%EXT%", []string{"index.php", "index.asp"}},
+		{"占位符前没有点号", Options{Extensions: []string{"php"}}, "index%EXT%", []string{"index.php"}},
+		{"没有扩展名时丢弃占位符", Options{}, "index.%EXT%", nil},
+		{
+			"强制扩展名",
+			Options{Extensions: []string{"php", "html"}, ForceExtensions: true},
+			"admin",
+			[]string{"admin", "admin.php", "admin.html"},
+		},
+		{
+			"强制扩展名跳过已有的扩展名",
+			Options{Extensions: []string{"php", "html"}, ForceExtensions: true},
+			"index.PHP",
+			[]string{"index.PHP", "index.PHP.html"},
+		},
+		{"强制扩展名跳过目录", Options{Extensions: []string{"php"}, ForceExtensions: true}, "admin/", []string{"admin/"}},
+		{"排除扩展名", Options{ExcludeExtensions: []string{".jpg"}}, "logo.JPG", nil},
+		{"去掉扩展名", Options{RemoveExtensions: true}, "dir/admin.tar.gz", []string{"dir/admin"}},
+		{"点号开头的文件名", Options{RemoveExtensions: true}, ".htaccess", []string{".htaccess"}},
+		{"大写", Options{Uppercase: true}, "Admin", []string{"ADMIN"}},
+		{"多种大小写去重", Options{Lowercase: true, Capitalize: true}, "admin", []string{"admin", "Admin"}},
+		{"首字母大写", Options{Capitalize: true}, "aDMIN", []string{"Admin"}},
+		{"前缀", Options{Prefixes: []string{".", "_"}}, "env", []string{"env", ".env", "_env"}},
+		{"已有前缀不重复添加", Options{Prefixes: []string{"."}}, ".git", []string{".git"}},
+		{"后缀", Options{Suffixes: []string{"~", ".bak"}}, "index.php", []string{"index.php", "index.php~", "index.php.bak"}},
+		{"目录不加后缀", Options{Suffixes: []string{"~"}}, "admin/", []string{"admin/"}},
+		{
+			"组合选项",
+			Options{Extensions: []string{"php"}, Uppercase: true, Suffixes: []string{"~"}},
+			"index.%EXT%",
+			[]string{"INDEX.PHP", "INDEX.PHP~"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Generate(tt.line); !slices.Equal(got, tt.want) {
+				t.Errorf("Generate(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("admin\nindex.%EXT%\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Load(path, Options{Extensions: []string{"php"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"admin", "index.php"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Load() = %q, want %q", got, want)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.txt"), Options{}); err == nil {
+		t.Error("词典文件不存在时 Load() 没有返回错误")
+	}
+}